@@ -0,0 +1,344 @@
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// -----------------------------------------------------------------------------
+// Filter iface + impl.
+// -----------------------------------------------------------------------------
+
+// Filter keeps track of keys which have been seen. It is what decides if a
+// value is a duplicate in NewReader and NewWriter.
+type Filter interface {
+	// Seen reports whether the key has been seen before, and marks it as seen.
+	Seen(key string) bool
+}
+
+// Forgetter is optionally implemented by filters which can unmark a key, i.e
+// make it count as not seen. NewWriter uses it to unmark keys of values which
+// failed to be written, such that they can be retried.
+type Forgetter interface {
+	Forget(key string)
+}
+
+// FilterImpl lets you implement Filter (and Forgetter) with functions. Place
+// them into "Impl" and "ImplForget" and they will be called by the "Seen"
+// and "Forget" methods, respectively.
+type FilterImpl struct {
+	Impl       func(key string) bool
+	ImplForget func(key string)
+}
+
+// Seen implements Filter by deferring to the internal "Impl" func.
+// If the internal "Impl" is not set, false will be returned.
+func (impl FilterImpl) Seen(key string) bool {
+	if impl.Impl == nil {
+		return false
+	}
+
+	return impl.Impl(key)
+}
+
+// Forget implements Forgetter by deferring to the internal "ImplForget" func.
+// If the internal "ImplForget" is not set, this is a no-op.
+func (impl FilterImpl) Forget(key string) {
+	if impl.ImplForget == nil {
+		return
+	}
+
+	impl.ImplForget(key)
+}
+
+// NewExactFilter returns a Filter which remembers every key in an in-memory set.
+// It never gives false positives, but memory grows with the number of keys.
+func NewExactFilter() Filter {
+	var mx sync.Mutex
+	set := make(map[string]struct{})
+
+	return FilterImpl{
+		Impl: func(key string) bool {
+			mx.Lock()
+			defer mx.Unlock()
+
+			if _, ok := set[key]; ok {
+				return true
+			}
+
+			set[key] = struct{}{}
+			return false
+		},
+		ImplForget: func(key string) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			delete(set, key)
+		},
+	}
+}
+
+type NewTTLFilterArgs struct {
+	// TTL is how long a key is remembered after it was first seen. On <= 0,
+	// keys are never forgotten, making the filter equivalent to NewExactFilter.
+	TTL time.Duration
+	// Now is used to get the current time. On nil, time.Now is used.
+	Now func() time.Time
+}
+
+// NewTTLFilter returns a Filter which remembers keys for args.TTL. Expired
+// keys are evicted lazily when Seen is called, so a key which re-appears
+// after it has expired is not considered a duplicate.
+func NewTTLFilter(args NewTTLFilterArgs) Filter {
+	if args.TTL <= 0 {
+		return NewExactFilter()
+	}
+	if args.Now == nil {
+		args.Now = time.Now
+	}
+
+	type entry struct {
+		key    string
+		expiry time.Time
+	}
+
+	var mx sync.Mutex
+	set := make(map[string]time.Time)
+	queue := make([]entry, 0, 8) // Ordered by expiry since TTL is constant.
+
+	return FilterImpl{
+		Impl: func(key string) bool {
+			mx.Lock()
+			defer mx.Unlock()
+
+			now := args.Now()
+			for len(queue) > 0 && !queue[0].expiry.After(now) {
+				e := queue[0]
+				queue = queue[1:]
+
+				if expiry, ok := set[e.key]; ok && expiry.Equal(e.expiry) {
+					delete(set, e.key)
+				}
+			}
+
+			if _, ok := set[key]; ok {
+				return true
+			}
+
+			e := entry{key: key, expiry: now.Add(args.TTL)}
+			set[key] = e.expiry
+			queue = append(queue, e)
+			return false
+		},
+		// Queue entries of forgotten keys are skipped on eviction, since their
+		// expiry does not match anything in the set.
+		ImplForget: func(key string) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			delete(set, key)
+		},
+	}
+}
+
+type NewBloomFilterArgs struct {
+	// Size is the expected number of unique keys. On <= 0, defaults to 1024.
+	Size int
+	// FPRate is the desired false positive rate, i.e the probability of a
+	// new key being reported as seen. On <= 0 or >= 1, defaults to 0.01.
+	FPRate float64
+}
+
+// NewBloomFilter returns a Filter which is backed by a Bloom filter sized by
+// args.Size and args.FPRate. It uses constant memory, but may report keys as
+// seen when they are not (false positives), in which case values are dropped.
+// It never reports a seen key as new, and keys can not be forgotten.
+func NewBloomFilter(args NewBloomFilterArgs) Filter {
+	if args.Size <= 0 {
+		args.Size = 1024
+	}
+	if args.FPRate <= 0 || args.FPRate >= 1 {
+		args.FPRate = 0.01
+	}
+
+	// Optimal number of bits (m) and hash funcs (k).
+	n := float64(args.Size)
+	m := math.Ceil(-n * math.Log(args.FPRate) / (math.Ln2 * math.Ln2))
+	k := int(math.Max(1, math.Round(m/n*math.Ln2)))
+
+	var mx sync.Mutex
+	bits := make([]uint64, (int(m)+63)/64)
+	size := uint64(len(bits) * 64)
+	seed1 := maphash.MakeSeed()
+	seed2 := maphash.MakeSeed()
+
+	return FilterImpl{
+		Impl: func(key string) bool {
+			// Double hashing, see Kirsch & Mitzenmacher.
+			h1 := maphash.String(seed1, key)
+			h2 := maphash.String(seed2, key) | 1
+
+			mx.Lock()
+			defer mx.Unlock()
+
+			seen := true
+			for i := 0; i < k; i++ {
+				j := (h1 + uint64(i)*h2) % size
+				if bits[j/64]&(1<<(j%64)) == 0 {
+					seen = false
+					bits[j/64] |= 1 << (j % 64)
+				}
+			}
+
+			return seen
+		},
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Counter.
+// -----------------------------------------------------------------------------
+
+// Counter counts values passing through readers and writers from this pkg.
+// It is safe for concurrent use and may be shared between several of them.
+type Counter struct {
+	total   atomic.Int64
+	dropped atomic.Int64
+}
+
+// Total returns the number of values which have been checked for duplicates.
+func (c *Counter) Total() int64 {
+	return c.total.Load()
+}
+
+// Dropped returns the number of values which were dropped as duplicates.
+func (c *Counter) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+func (c *Counter) add(dropped bool) {
+	if c == nil {
+		return
+	}
+
+	c.total.Add(1)
+	if dropped {
+		c.dropped.Add(1)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Constructors.
+// -----------------------------------------------------------------------------
+
+type NewReaderArgs[T any] struct {
+	// Reader is what the func reads from. On nil, the func simply returns
+	// a core.ReaderImpl[T], making it pointless.
+	Reader core.Reader[T]
+	// Key is used to identify values. On nil, fmt.Sprint is used.
+	Key func(T) string
+	// Filter decides what is a duplicate. On nil, NewExactFilter is used.
+	Filter Filter
+	// Counter is updated on each value read from Reader. On nil, nothing
+	// is counted.
+	Counter *Counter
+}
+
+// NewReader returns a Reader which reads from args.Reader while skipping
+// values with a key which has been seen before. See args for details.
+//
+// Example:
+//
+//	r := NewReader(NewReaderArgs[int]{Reader: core.NewReaderFrom(1, 2, 1, 3)})
+//
+//	t.Log(r.Read(nil)) // 1, nil
+//	t.Log(r.Read(nil)) // 2, nil
+//	t.Log(r.Read(nil)) // 3, nil
+//	t.Log(r.Read(nil)) // 0, io.EOF
+func NewReader[T any](args NewReaderArgs[T]) core.Reader[T] {
+	if args.Reader == nil {
+		return core.ReaderImpl[T]{}
+	}
+	if args.Key == nil {
+		args.Key = func(v T) string { return fmt.Sprint(v) }
+	}
+	if args.Filter == nil {
+		args.Filter = NewExactFilter()
+	}
+
+	return core.ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			for {
+				val, err = args.Reader.Read(ctx)
+				if err != nil {
+					return
+				}
+
+				seen := args.Filter.Seen(args.Key(val))
+				args.Counter.add(seen)
+
+				if !seen {
+					return
+				}
+			}
+		},
+	}
+}
+
+type NewWriterArgs[T any] struct {
+	// Writer is what the returned Writer writes to. On nil, the func simply
+	// returns a core.WriterImpl[T], making it pointless.
+	Writer core.Writer[T]
+	// Key is used to identify values. On nil, fmt.Sprint is used.
+	Key func(T) string
+	// Filter decides what is a duplicate. On nil, NewExactFilter is used.
+	Filter Filter
+	// Counter is updated on each value written to the returned Writer. On nil,
+	// nothing is counted.
+	Counter *Counter
+}
+
+// NewWriter returns a Writer which writes to args.Writer, except for values
+// with a key which has been seen before; those are silently dropped (nil err).
+// See args for details.
+//
+// If args.Writer returns an err, then the key is forgotten if args.Filter
+// implements Forgetter (all filters in this pkg do, except NewBloomFilter),
+// such that the value may be retried. Otherwise, retries are dropped.
+func NewWriter[T any](args NewWriterArgs[T]) core.Writer[T] {
+	if args.Writer == nil {
+		return core.WriterImpl[T]{}
+	}
+	if args.Key == nil {
+		args.Key = func(v T) string { return fmt.Sprint(v) }
+	}
+	if args.Filter == nil {
+		args.Filter = NewExactFilter()
+	}
+
+	return core.WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			key := args.Key(val)
+			seen := args.Filter.Seen(key)
+			args.Counter.add(seen)
+
+			if seen {
+				return
+			}
+
+			err = args.Writer.Write(ctx, val)
+			if f, ok := args.Filter.(Forgetter); ok && err != nil {
+				f.Forget(key)
+			}
+
+			return err
+		},
+	}
+}
@@ -0,0 +1,216 @@
+package dedup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func assertEq[T any](subject string, want T, have T, f func(string)) {
+	if f == nil {
+		return
+	}
+
+	ab, _ := json.Marshal(want)
+	bb, _ := json.Marshal(have)
+
+	as := string(ab)
+	bs := string(bb)
+
+	if as == bs {
+		return
+	}
+
+	s := "unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n"
+	f(fmt.Sprintf(s, subject, as, bs))
+}
+
+func tfReadAll[T any](ctx context.Context, r core.Reader[T]) ([]T, error) {
+	var v T
+	var s = make([]T, 0, 8)
+	var err error
+
+	for v, err = r.Read(ctx); err == nil; v, err = r.Read(ctx) {
+		s = append(s, v)
+	}
+
+	return s, err
+}
+
+// -----------------------------------------------------------------------------
+// Tests: Filters.
+// -----------------------------------------------------------------------------
+
+func TestNewExactFilterIdeal(t *testing.T) {
+	f := NewExactFilter()
+
+	assertEq("seen", false, f.Seen("a"), func(s string) { t.Fatal(s) })
+	assertEq("seen", false, f.Seen("b"), func(s string) { t.Fatal(s) })
+	assertEq("seen", true, f.Seen("a"), func(s string) { t.Fatal(s) })
+}
+
+func TestNewTTLFilterIdeal(t *testing.T) {
+	now := time.Now()
+	f := NewTTLFilter(NewTTLFilterArgs{TTL: time.Second, Now: func() time.Time { return now }})
+
+	assertEq("seen", false, f.Seen("a"), func(s string) { t.Fatal(s) })
+	assertEq("seen", true, f.Seen("a"), func(s string) { t.Fatal(s) })
+
+	now = now.Add(time.Second)
+	assertEq("seen", false, f.Seen("a"), func(s string) { t.Fatal(s) })
+	assertEq("seen", true, f.Seen("a"), func(s string) { t.Fatal(s) })
+
+	f.(Forgetter).Forget("a")
+	assertEq("seen", false, f.Seen("a"), func(s string) { t.Fatal(s) })
+}
+
+func TestNewBloomFilterIdeal(t *testing.T) {
+	// Sized for 2000 since checks below mark new keys as seen as well.
+	f := NewBloomFilter(NewBloomFilterArgs{Size: 2000, FPRate: 0.01})
+
+	for i := 0; i < 1000; i++ {
+		f.Seen(strconv.Itoa(i))
+	}
+
+	// No false negatives.
+	for i := 0; i < 1000; i++ {
+		assertEq("seen", true, f.Seen(strconv.Itoa(i)), func(s string) { t.Fatal(s) })
+	}
+
+	// False positives should roughly match the configured rate.
+	fp := 0
+	for i := 1000; i < 2000; i++ {
+		if f.Seen(strconv.Itoa(i)) {
+			fp++
+		}
+	}
+
+	if fp > 50 {
+		t.Fatalf("unexpected false positive count: %v", fp)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewReader.
+// -----------------------------------------------------------------------------
+
+func TestNewReaderIdeal(t *testing.T) {
+	c := &Counter{}
+	r := NewReader(
+		NewReaderArgs[int]{
+			Reader:  core.NewReaderFrom(1, 2, 1, 3, 2),
+			Key:     func(v int) string { return strconv.Itoa(v) },
+			Counter: c,
+		},
+	)
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2, 3}, vals, func(s string) { t.Fatal(s) })
+	assertEq("total", 5, c.Total(), func(s string) { t.Fatal(s) })
+	assertEq("dropped", 2, c.Dropped(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithNilReader(t *testing.T) {
+	r := NewReader(NewReaderArgs[int]{})
+
+	_, err := r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithNilCounter(t *testing.T) {
+	r := NewReader(NewReaderArgs[int]{Reader: core.NewReaderFrom(1, 1)})
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1}, vals, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewWriter.
+// -----------------------------------------------------------------------------
+
+func TestNewWriterIdeal(t *testing.T) {
+	c := &Counter{}
+	rw := core.NewReadWriterFrom[int]()
+	w := NewWriter(NewWriterArgs[int]{Writer: rw, Counter: c})
+
+	for _, v := range []int{1, 2, 1, 3, 2} {
+		err := w.Write(context.Background(), v)
+		assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	}
+
+	vals, err := tfReadAll(context.Background(), core.Reader[int](rw))
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2, 3}, vals, func(s string) { t.Fatal(s) })
+	assertEq("total", 5, c.Total(), func(s string) { t.Fatal(s) })
+	assertEq("dropped", 2, c.Dropped(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithNilWriter(t *testing.T) {
+	w := NewWriter(NewWriterArgs[int]{})
+
+	err := w.Write(context.Background(), 1)
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithRetryAfterErr(t *testing.T) {
+	fail := true
+	vals := make([]int, 0, 2)
+	w := NewWriter(
+		NewWriterArgs[int]{
+			Writer: core.WriterImpl[int]{
+				Impl: func(ctx context.Context, v int) error {
+					if fail {
+						return io.ErrUnexpectedEOF
+					}
+
+					vals = append(vals, v)
+					return nil
+				},
+			},
+		},
+	)
+
+	err := w.Write(context.Background(), 1)
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+
+	fail = false
+	err = w.Write(context.Background(), 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithRetryAfterErrAndBloomFilter(t *testing.T) {
+	fail := true
+	vals := make([]int, 0, 2)
+	w := NewWriter(
+		NewWriterArgs[int]{
+			Writer: core.WriterImpl[int]{
+				Impl: func(ctx context.Context, v int) error {
+					if fail {
+						return io.ErrUnexpectedEOF
+					}
+
+					vals = append(vals, v)
+					return nil
+				},
+			},
+			Filter: NewBloomFilter(NewBloomFilterArgs{}),
+		},
+	)
+
+	w.Write(context.Background(), 1)
+
+	// Bloom filters can not forget, so the retry is dropped.
+	fail = false
+	err := w.Write(context.Background(), 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{}, vals, func(s string) { t.Fatal(s) })
+}
@@ -2,14 +2,61 @@ package eventloop
 
 import (
 	"context"
+	"sync/atomic"
+	"time"
 
 	"github.com/crunchypi/gtl/core"
 )
 
+// Stats contains instrumentation for a single value which went through the
+// loop started with New, it is meant for finding out if the Reader or Writer
+// is the bottleneck. Fields with the "Total" suffix are cumulative since the
+// loop started.
+type Stats struct {
+	// ReadWait is the time spent in Reader.Read for this value.
+	ReadWait time.Duration `json:"readWait"`
+	// WriteWait is the time spent in Writer.Write for this value.
+	WriteWait time.Duration `json:"writeWait"`
+	// DequeueWait is the time the writing side waited for this value to be
+	// available in the queue, i.e time where the Writer was starved. Always
+	// 0 when NewArgs.Buffer <= 0.
+	DequeueWait time.Duration `json:"dequeueWait"`
+
+	ReadTotal    time.Duration `json:"readTotal"`
+	WriteTotal   time.Duration `json:"writeTotal"`
+	DequeueTotal time.Duration `json:"dequeueTotal"`
+	// EnqueueTotal is the time the reading side waited for space in the
+	// queue, i.e time where the Reader was held back by the Writer. Always
+	// 0 when NewArgs.Buffer <= 0.
+	EnqueueTotal time.Duration `json:"enqueueTotal"`
+
+	// QueueLen is the number of values in the queue after this value was
+	// taken out of it, QueueCap is NewArgs.Buffer.
+	QueueLen int `json:"queueLen"`
+	QueueCap int `json:"queueCap"`
+
+	// Count is the number of values written so far, including this one.
+	Count int       `json:"count"`
+	Stamp time.Time `json:"stamp"`
+}
+
 type NewArgs[T any] struct {
 	Ctx    context.Context
 	Reader core.Reader[T]
 	Writer core.Writer[T]
+	// Buffer is the size of a queue between Reader and Writer. If > 0, then
+	// reading and writing is done in separate goroutines, such that the Reader
+	// may read ahead of the Writer. If <= 0, values are read and written one
+	// at a time in a single goroutine.
+	Buffer int
+	// Stats receives one Stats record for each value written to Writer. On nil,
+	// no stats are made. Errors coming from here are ignored.
+	Stats core.Writer[Stats]
+}
+
+type item[T any] struct {
+	val      T
+	readWait time.Duration
 }
 
 // New spawns a new goroutine in which values are read from args.Reader and
@@ -21,6 +68,14 @@ type NewArgs[T any] struct {
 // for anything besides breaking the internal loop, you are intended to pick them
 // up with decorators around the Reader and Writer. Also see pkg stats and log.
 //
+// If args.Buffer > 0, then reading happens in a separate goroutine and values
+// are passed to the writing goroutine through a queue of that size. When the
+// loop stops by itself, the returned ctx is done only after any Read in
+// progress has returned, so args.Reader may be closed after <-ctx.Done(). If
+// args.Stats is set, then time spent in reading, writing and waiting on the
+// queue is measured and written there (see Stats), which is useful for
+// diagnosing slow stages.
+//
 // Examples (interactive):
 //   - https://go.dev/play/p/bPO8cOXpyqW
 func New[T any](args NewArgs[T]) (ctx context.Context, ctxCancel context.CancelFunc) {
@@ -38,27 +93,126 @@ func New[T any](args NewArgs[T]) (ctx context.Context, ctxCancel context.CancelF
 		return
 	}
 
+	if args.Buffer <= 0 {
+		go loop(ctx, ctxCancel, args)
+	} else {
+		go loopBuffered(ctx, ctxCancel, args)
+	}
+
+	return
+}
+
+func loop[T any](ctx context.Context, ctxCancel context.CancelFunc, args NewArgs[T]) {
+	defer ctxCancel()
+
+	stats := Stats{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		ts := time.Now()
+		v, err := args.Reader.Read(ctx)
+		if err != nil {
+			return
+		}
+
+		stats.ReadWait = time.Since(ts)
+
+		ts = time.Now()
+		err = args.Writer.Write(ctx, v)
+		if err != nil {
+			return
+		}
+
+		stats.WriteWait = time.Since(ts)
+
+		if args.Stats != nil {
+			stats.ReadTotal += stats.ReadWait
+			stats.WriteTotal += stats.WriteWait
+			stats.Count++
+			stats.Stamp = time.Now()
+			args.Stats.Write(ctx, stats)
+		}
+	}
+}
+
+func loopBuffered[T any](ctx context.Context, ctxCancel context.CancelFunc, args NewArgs[T]) {
+	defer ctxCancel()
+
+	ch := make(chan item[T], args.Buffer)
+	enqueueTotal := atomic.Int64{}
+
+	// The reading side has its own ctx, such that the writing side can stop
+	// it and wait for any Read in progress before ctx is cancelled.
+	readCtx, readCancel := context.WithCancel(ctx)
+	defer func() {
+		readCancel()
+		for range ch {
+		}
+	}()
+
+	// Reading side.
 	go func() {
-		defer ctxCancel()
+		defer close(ch)
 
 		for {
 			select {
-			case <-ctx.Done():
-				break
+			case <-readCtx.Done():
+				return
 			default:
 			}
 
-			v, err := args.Reader.Read(ctx)
+			ts := time.Now()
+			v, err := args.Reader.Read(readCtx)
 			if err != nil {
-				break
+				return
 			}
 
-			err = args.Writer.Write(ctx, v)
-			if err != nil {
-				break
+			it := item[T]{val: v, readWait: time.Since(ts)}
+
+			ts = time.Now()
+			select {
+			case <-readCtx.Done():
+				return
+			case ch <- it:
 			}
+
+			enqueueTotal.Add(int64(time.Since(ts)))
 		}
 	}()
 
-	return
+	// Writing side.
+	stats := Stats{QueueCap: args.Buffer}
+	for {
+		ts := time.Now()
+		it, ok := <-ch
+		if !ok {
+			return
+		}
+
+		stats.DequeueWait = time.Since(ts)
+		stats.QueueLen = len(ch)
+
+		ts = time.Now()
+		err := args.Writer.Write(ctx, it.val)
+		if err != nil {
+			return
+		}
+
+		stats.ReadWait = it.readWait
+		stats.WriteWait = time.Since(ts)
+
+		if args.Stats != nil {
+			stats.ReadTotal += stats.ReadWait
+			stats.WriteTotal += stats.WriteWait
+			stats.DequeueTotal += stats.DequeueWait
+			stats.EnqueueTotal = time.Duration(enqueueTotal.Load())
+			stats.Count++
+			stats.Stamp = time.Now()
+			args.Stats.Write(ctx, stats)
+		}
+	}
 }
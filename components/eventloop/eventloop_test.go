@@ -2,6 +2,8 @@ package eventloop
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -130,3 +132,154 @@ func TestNewWithCancel(t *testing.T) {
 		t.Fatal("test hung")
 	}
 }
+
+func newSliceWriter[T any](mx *sync.Mutex, s *[]T) core.Writer[T] {
+	return core.WriterImpl[T]{
+		Impl: func(ctx context.Context, v T) error {
+			mx.Lock()
+			defer mx.Unlock()
+			*s = append(*s, v)
+			return nil
+		},
+	}
+}
+
+func newWriterWithSleep[T any](w core.Writer[T], d time.Duration) core.Writer[T] {
+	return core.WriterImpl[T]{
+		Impl: func(ctx context.Context, v T) error {
+			time.Sleep(d)
+			return w.Write(ctx, v)
+		},
+	}
+}
+
+func TestNewWithStats(t *testing.T) {
+	mx := sync.Mutex{}
+	vals := make([]int, 0, 3)
+	stats := make([]Stats, 0, 3)
+
+	args := NewArgs[int]{}
+	args.Reader = core.NewReaderFrom(1, 2, 3)
+	args.Writer = newWriterWithSleep(newSliceWriter(&mx, &vals), time.Millisecond*10)
+	args.Stats = newSliceWriter(&mx, &stats)
+
+	ctx, _ := New(args)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second * 3):
+		t.Fatal("test hung")
+	}
+
+	mx.Lock()
+	defer mx.Unlock()
+
+	if len(vals) != 3 || len(stats) != 3 {
+		t.Fatalf("unexpected lens: %v, %v", len(vals), len(stats))
+	}
+
+	last := stats[len(stats)-1]
+	if last.Count != 3 || last.WriteTotal < time.Millisecond*30 || last.WriteTotal < last.ReadTotal {
+		t.Fatalf("unexpected stats: %+v", last)
+	}
+}
+
+func TestNewWithBuffer(t *testing.T) {
+	mx := sync.Mutex{}
+	vals := make([]int, 0, 5)
+	stats := make([]Stats, 0, 5)
+
+	args := NewArgs[int]{}
+	args.Reader = core.NewReaderFrom(1, 2, 3, 4, 5)
+	args.Writer = newWriterWithSleep(newSliceWriter(&mx, &vals), time.Millisecond*10)
+	args.Buffer = 2
+	args.Stats = newSliceWriter(&mx, &stats)
+
+	ctx, _ := New(args)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second * 3):
+		t.Fatal("test hung")
+	}
+
+	mx.Lock()
+	defer mx.Unlock()
+
+	if len(vals) != 5 || len(stats) != 5 {
+		t.Fatalf("unexpected lens: %v, %v", len(vals), len(stats))
+	}
+
+	for i, v := range vals {
+		if v != i+1 {
+			t.Fatalf("unexpected order: %v", vals)
+		}
+	}
+
+	// The reader is fast and the writer slow, so the reader should have
+	// been held back by the full queue.
+	last := stats[len(stats)-1]
+	if last.QueueCap != 2 || last.EnqueueTotal <= 0 || last.Count != 5 {
+		t.Fatalf("unexpected stats: %+v", last)
+	}
+}
+
+func TestNewWithBufferAndCancel(t *testing.T) {
+	args := NewArgs[int]{}
+	args.Reader = core.NewReaderFrom(1, 2, 3)
+	args.Writer = newWriterWithSleep1s(newWriterWithNop[int]())
+	args.Buffer = 1
+
+	ctx, ctxCancel := New(args)
+	ctxCancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second * 3):
+		t.Fatal("test hung")
+	}
+}
+
+func TestNewWithBufferAndWriterErr(t *testing.T) {
+	args := NewArgs[int]{}
+	args.Reader = core.ReaderImpl[int]{Impl: func(ctx context.Context) (int, error) { return 1, nil }}
+	args.Writer = core.WriterImpl[int]{}
+	args.Buffer = 1
+
+	ctx, _ := New(args)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second * 3):
+		t.Fatal("test hung")
+	}
+}
+
+func TestNewWithBufferAndWriterErrWhileReading(t *testing.T) {
+	reading := atomic.Bool{}
+
+	args := NewArgs[int]{}
+	args.Reader = core.ReaderImpl[int]{
+		Impl: func(ctx context.Context) (int, error) {
+			reading.Store(true)
+			defer reading.Store(false)
+
+			time.Sleep(time.Millisecond * 10)
+			return 1, nil
+		},
+	}
+	args.Writer = core.WriterImpl[int]{}
+	args.Buffer = 1
+
+	ctx, _ := New(args)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second * 3):
+		t.Fatal("test hung")
+	}
+
+	if reading.Load() {
+		t.Fatal("ctx done while Reader.Read is running")
+	}
+}
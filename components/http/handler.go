@@ -0,0 +1,292 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	stdhttp "net/http"
+	"strings"
+	"sync"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+type NewReaderHandlerArgs[T any] struct {
+	// Reader is streamed to clients. On nil, clients get an empty stream.
+	Reader core.Reader[T]
+	// SSE makes values be streamed as server-sent events (one "data" event
+	// per value), instead of newline delimited JSON.
+	SSE bool
+	// Encoder encodes values. On nil, defaults to json.NewEncoder.
+	Encoder func(io.Writer) core.Encoder
+}
+
+// NewReaderHandler returns a handler which streams values from args.Reader to
+// clients, either as NDJSON (Content-Type "application/x-ndjson") or as
+// server-sent events (Content-Type "text/event-stream"), flushing after each
+// value. Streaming stops when args.Reader gives an err (io.EOF ends the stream
+// normally) or when the client disconnects (i.e the request ctx is done), the
+// request ctx is given to args.Reader.
+//
+// If args.Reader gives an err other than io.EOF before anything is written,
+// then the response is 500. Later errs end the stream, with an "error" event
+// if args.SSE is true. Reads are guarded by a mutex, such that concurrent
+// clients each get a share of the values from args.Reader. Clients which have
+// disconnected do not read, and a value which was read is written even if the
+// client disconnects in the meantime. Note that such a value is lost, as are
+// values which were written but not received before the client disconnected.
+//
+// Example:
+//
+//	stdhttp.Handle("/events", NewReaderHandler(
+//	    NewReaderHandlerArgs[event]{Reader: myReader, SSE: true},
+//	))
+func NewReaderHandler[T any](args NewReaderHandlerArgs[T]) stdhttp.Handler {
+	if args.Reader == nil {
+		args.Reader = core.ReaderImpl[T]{}
+	}
+	if args.Encoder == nil {
+		args.Encoder = func(w io.Writer) core.Encoder { return json.NewEncoder(w) }
+	}
+
+	mx := sync.Mutex{}
+	read := func(ctx context.Context) (T, error) {
+		mx.Lock()
+		defer mx.Unlock()
+
+		// The client may have left while waiting for the lock.
+		if err := ctx.Err(); err != nil {
+			return *new(T), err
+		}
+
+		return args.Reader.Read(ctx)
+	}
+
+	contentType := "application/x-ndjson"
+	if args.SSE {
+		contentType = "text/event-stream"
+	}
+
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		ctx := r.Context()
+		rc := stdhttp.NewResponseController(w)
+		buf := bytes.NewBuffer(nil)
+		enc := args.Encoder(buf)
+
+		for n := 0; ; n++ {
+			v, err := read(ctx)
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+			if err != nil && n == 0 && err != io.EOF {
+				stdhttp.Error(w, err.Error(), stdhttp.StatusInternalServerError)
+				return
+			}
+			if n == 0 {
+				w.Header().Set("Content-Type", contentType)
+				w.Header().Set("Cache-Control", "no-cache")
+				w.WriteHeader(stdhttp.StatusOK)
+			}
+			if err != nil {
+				if err != io.EOF && args.SSE {
+					writeEvent(w, "error", err.Error())
+					rc.Flush()
+				}
+
+				return
+			}
+
+			buf.Reset()
+			if err = enc.Encode(v); err != nil {
+				return
+			}
+
+			if args.SSE {
+				err = writeEvent(w, "", strings.TrimSuffix(buf.String(), "\n"))
+			} else {
+				_, err = w.Write(buf.Bytes())
+			}
+
+			if err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	})
+}
+
+// writeEvent writes a server-sent event, multi-line data is split into several
+// "data" fields. An 'event' of "" omits the event field.
+func writeEvent(w io.Writer, event string, data string) error {
+	b := strings.Builder{}
+	if event != "" {
+		b.WriteString("event: " + event + "\n")
+	}
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type NewWriterHandlerArgs[T any] struct {
+	// Writer receives values from request bodies. On nil, all requests get
+	// 503, as with io.ErrClosedPipe.
+	Writer core.Writer[T]
+	// Decoder decodes values from request bodies. On nil, defaults to
+	// json.NewDecoder, in which case bodies may also be a JSON array.
+	Decoder func(io.Reader) core.Decoder
+	// MaxBytes limits the size of request bodies. On <= 0, there is no limit.
+	MaxBytes int64
+}
+
+// NewWriterHandler returns a handler which accepts POST or PUT requests with
+// values in the body, and writes them to args.Writer, one at a time while the
+// body is read, which gives backpressure to clients. With the default decoder,
+// bodies may be NDJSON (or any sequence of JSON values) or a JSON array.
+//
+// Response statuses:
+//   - 204: All values were written.
+//   - 400: The body could not be decoded, values before that were written.
+//   - 405: The method is not POST or PUT.
+//   - 413: The body is larger than args.MaxBytes.
+//   - 500: args.Writer gave an err other than io.ErrClosedPipe.
+//   - 503: args.Writer gave io.ErrClosedPipe, i.e it does not accept values.
+//
+// Error responses include the number of values which were written. Writes are
+// guarded by a mutex, and are given the request ctx.
+//
+// Example:
+//
+//	stdhttp.Handle("/ingest", NewWriterHandler(
+//	    NewWriterHandlerArgs[event]{Writer: myWriter, MaxBytes: 10 << 20},
+//	))
+func NewWriterHandler[T any](args NewWriterHandlerArgs[T]) stdhttp.Handler {
+	if args.Writer == nil {
+		args.Writer = core.WriterImpl[T]{}
+	}
+
+	mx := sync.Mutex{}
+	write := func(ctx context.Context, v T) error {
+		mx.Lock()
+		defer mx.Unlock()
+
+		return args.Writer.Write(ctx, v)
+	}
+
+	return stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		if r.Method != stdhttp.MethodPost && r.Method != stdhttp.MethodPut {
+			w.Header().Set("Allow", "POST, PUT")
+			stdhttp.Error(w, "method not allowed", stdhttp.StatusMethodNotAllowed)
+			return
+		}
+
+		body := io.Reader(r.Body)
+		if args.MaxBytes > 0 {
+			body = stdhttp.MaxBytesReader(w, r.Body, args.MaxBytes)
+		}
+
+		ctx := r.Context()
+		next := newBodyDecoder[T](body, args.Decoder)
+
+		n := 0
+		fail := func(code int, err error) {
+			stdhttp.Error(w, fmt.Sprintf("%v (%d values written)", err, n), code)
+		}
+
+		for {
+			v, err := next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				var maxErr *stdhttp.MaxBytesError
+				if errors.As(err, &maxErr) {
+					fail(stdhttp.StatusRequestEntityTooLarge, err)
+					return
+				}
+
+				fail(stdhttp.StatusBadRequest, err)
+				return
+			}
+
+			err = write(ctx, v)
+			if err == io.ErrClosedPipe {
+				fail(stdhttp.StatusServiceUnavailable, err)
+				return
+			}
+			if err != nil {
+				fail(stdhttp.StatusInternalServerError, err)
+				return
+			}
+
+			n++
+		}
+
+		w.WriteHeader(stdhttp.StatusNoContent)
+	})
+}
+
+// newBodyDecoder returns a func which decodes the next value from 'r', with
+// io.EOF at the end. If 'f' is nil, then json is used, and a body which is a
+// JSON array is decoded element by element.
+func newBodyDecoder[T any](r io.Reader, f func(io.Reader) core.Decoder) func() (T, error) {
+	if f != nil {
+		dec := f(r)
+		return func() (v T, err error) {
+			err = dec.Decode(&v)
+			return
+		}
+	}
+
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+
+	// Peek the first non-space byte to see if the body is an array.
+	isArray := false
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			break
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			br.ReadByte()
+			continue
+		}
+
+		isArray = b[0] == '['
+		break
+	}
+
+	if !isArray {
+		return func() (v T, err error) {
+			err = dec.Decode(&v)
+			return
+		}
+	}
+
+	started := false
+	return func() (v T, err error) {
+		if !started {
+			started = true
+			if _, err = dec.Token(); err != nil {
+				return v, err
+			}
+		}
+		if !dec.More() {
+			if _, err = dec.Token(); err != nil { // Closing ']'.
+				return v, err
+			}
+
+			return v, io.EOF
+		}
+
+		err = dec.Decode(&v)
+		return
+	}
+}
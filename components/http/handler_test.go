@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// -----------------------------------------------------------------------------
+// Tests: NewReaderHandler.
+// -----------------------------------------------------------------------------
+
+func TestNewReaderHandlerIdeal(t *testing.T) {
+	h := NewReaderHandler(NewReaderHandlerArgs[int]{Reader: core.NewReaderFrom(1, 2, 3)})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	assertEq("code", 200, rec.Code, func(s string) { t.Fatal(s) })
+	assertEq("type", "application/x-ndjson", rec.Header().Get("Content-Type"), func(s string) { t.Fatal(s) })
+	assertEq("body", "1\n2\n3\n", rec.Body.String(), func(s string) { t.Fatal(s) })
+	assertEq("flushed", true, rec.Flushed, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderHandlerWithSSE(t *testing.T) {
+	r := core.NewReaderWithConcat[string](
+		core.NewReaderFrom("a"),
+		core.ReaderImpl[string]{
+			Impl: func(ctx context.Context) (string, error) { return "", io.ErrUnexpectedEOF },
+		},
+	)
+
+	rec := httptest.NewRecorder()
+	NewReaderHandler(NewReaderHandlerArgs[string]{Reader: r, SSE: true}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	want := "data: \"a\"\n\nevent: error\ndata: unexpected EOF\n\n"
+	assertEq("type", "text/event-stream", rec.Header().Get("Content-Type"), func(s string) { t.Fatal(s) })
+	assertEq("body", want, rec.Body.String(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderHandlerWithErr(t *testing.T) {
+	r := core.ReaderImpl[int]{
+		Impl: func(ctx context.Context) (int, error) { return 0, io.ErrUnexpectedEOF },
+	}
+
+	rec := httptest.NewRecorder()
+	NewReaderHandler(NewReaderHandlerArgs[int]{Reader: r}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	assertEq("code", 500, rec.Code, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderHandlerWithDisconnect(t *testing.T) {
+	n := 0
+	r := core.ReaderImpl[int]{
+		Impl: func(ctx context.Context) (int, error) {
+			n++
+			return n, nil // Infinite.
+		},
+	}
+
+	srv := httptest.NewServer(NewReaderHandler(NewReaderHandlerArgs[int]{Reader: r}))
+	defer srv.Close()
+
+	resp, err := stdhttp.Get(srv.URL)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	sc := bufio.NewScanner(resp.Body)
+	for i := 0; i < 3 && sc.Scan(); i++ {
+	}
+
+	assertEq("line", "3", sc.Text(), func(s string) { t.Fatal(s) })
+
+	// The handler returns when the client is gone, else srv.Close blocks.
+	resp.Body.Close()
+}
+
+func TestNewReaderHandlerWithCtxDone(t *testing.T) {
+	r := core.NewReaderFrom(1, 2)
+	h := NewReaderHandler(NewReaderHandlerArgs[int]{Reader: r})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A client which is gone does not take values from others.
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assertEq("body", "1\n2\n", rec.Body.String(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderHandlerWithCtxDoneWhileReading(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := core.ReaderImpl[int]{
+		Impl: func(ctx context.Context) (int, error) {
+			cancel()
+			return 1, nil
+		},
+	}
+
+	// The value was read, so it is written rather than discarded.
+	rec := httptest.NewRecorder()
+	NewReaderHandler(NewReaderHandlerArgs[int]{Reader: r}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	assertEq("body", "1\n", rec.Body.String(), func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewWriterHandler.
+// -----------------------------------------------------------------------------
+
+func tfPost(h stdhttp.Handler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	return rec
+}
+
+func TestNewWriterHandlerIdeal(t *testing.T) {
+	for _, body := range []string{"1\n2\n3\n", " [1, 2, 3]", ""} {
+		rw := core.NewReadWriterFrom[int]()
+		rec := tfPost(NewWriterHandler(NewWriterHandlerArgs[int]{Writer: rw}), body)
+		assertEq("code", 204, rec.Code, func(s string) { t.Fatal(s) })
+
+		want := []int{1, 2, 3}
+		if body == "" {
+			want = []int{}
+		}
+
+		vals, _ := tfReadAll(context.Background(), core.Reader[int](rw))
+		assertEq("vals", want, vals, func(s string) { t.Fatal(s) })
+	}
+}
+
+func TestNewWriterHandlerWithDecodeErr(t *testing.T) {
+	rw := core.NewReadWriterFrom[int]()
+	rec := tfPost(NewWriterHandler(NewWriterHandlerArgs[int]{Writer: rw}), `[1, "a"]`)
+
+	assertEq("code", 400, rec.Code, func(s string) { t.Fatal(s) })
+	if !strings.Contains(rec.Body.String(), "(1 values written)") {
+		t.Fatalf("unexpected body: %v", rec.Body.String())
+	}
+}
+
+func TestNewWriterHandlerWithClosedPipe(t *testing.T) {
+	w := core.NewWriterWithTake[int](core.NewReadWriterFrom[int](), 1)
+	rec := tfPost(NewWriterHandler(NewWriterHandlerArgs[int]{Writer: w}), "1 2")
+
+	assertEq("code", 503, rec.Code, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterHandlerWithWriteErr(t *testing.T) {
+	w := core.WriterImpl[int]{
+		Impl: func(ctx context.Context, v int) error { return io.ErrShortWrite },
+	}
+
+	rec := tfPost(NewWriterHandler(NewWriterHandlerArgs[int]{Writer: w}), "1")
+	assertEq("code", 500, rec.Code, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterHandlerWithMaxBytes(t *testing.T) {
+	rw := core.NewReadWriterFrom[int]()
+	rec := tfPost(NewWriterHandler(NewWriterHandlerArgs[int]{Writer: rw, MaxBytes: 4}), "1\n2\n3\n")
+
+	assertEq("code", 413, rec.Code, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterHandlerWithMethod(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewWriterHandler(NewWriterHandlerArgs[int]{}).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	assertEq("code", 405, rec.Code, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterHandlerWithNilWriter(t *testing.T) {
+	rec := tfPost(NewWriterHandler(NewWriterHandlerArgs[int]{}), "1")
+	assertEq("code", 503, rec.Code, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdhttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusError is given when a response has a status code outside of 2xx.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// RetryAfter is parsed from the Retry-After header, it is 0 if the header
+	// is not set or is invalid.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http: unexpected status: %s", e.Status)
+}
+
+// Retryable reports whether the request may succeed if it is retried, which is
+// the case for 408 (timeout), 425 (too early), 429 (too many requests) and 5xx
+// other than 501 (not implemented) and 505 (version not supported).
+func (e *StatusError) Retryable() bool {
+	switch e.StatusCode {
+	case stdhttp.StatusRequestTimeout, stdhttp.StatusTooEarly, stdhttp.StatusTooManyRequests:
+		return true
+	case stdhttp.StatusNotImplemented, stdhttp.StatusHTTPVersionNotSupported:
+		return false
+	}
+
+	return e.StatusCode >= 500
+}
+
+// checkStatus returns a *StatusError if 'resp' is not 2xx, in which case the
+// body is drained and closed, such that the connection may be reused.
+func checkStatus(resp *stdhttp.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// retryAfter parses a Retry-After header value, which is either seconds or an
+// HTTP date. Invalid values and dates in the past give 0.
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if t, err := stdhttp.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+
+	return 0
+}
+
+func newGetRequest(ctx context.Context, u string) (*stdhttp.Request, error) {
+	req, err := stdhttp.NewRequestWithContext(ctx, stdhttp.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// resolve resolves 'ref' against 'base', e.g for relative next links.
+func resolve(base *url.URL, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(u).String(), nil
+}
+
+// linkNext returns the target of rel="next" in RFC 5988 Link headers, or ""
+// if there is none. Targets may contain ',' and ';', so they are parsed before
+// the params which follow them.
+func linkNext(h stdhttp.Header) string {
+	for _, line := range h.Values("Link") {
+		for s := line; ; {
+			i := strings.IndexByte(s, '<')
+			if i < 0 {
+				break
+			}
+
+			j := strings.IndexByte(s[i:], '>')
+			if j < 0 {
+				break
+			}
+
+			target := s[i+1 : i+j]
+			params, rest := linkParams(s[i+j+1:])
+			for _, param := range params {
+				k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(k), "rel") {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(v), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target
+					}
+				}
+			}
+
+			s = rest
+		}
+	}
+
+	return ""
+}
+
+// linkParams splits the params of a link (what follows "<target>") on ';',
+// up to the ',' which ends the link. Quoted strings are respected. The rest
+// of 's', after that ',', is returned as well.
+func linkParams(s string) (params []string, rest string) {
+	quoted := false
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\' && quoted:
+			i++
+		case c == ';' && !quoted:
+			params = append(params, s[start:i])
+			start = i + 1
+		case c == ',' && !quoted:
+			return append(params, s[start:i]), s[i+1:]
+		}
+	}
+
+	return append(params, s[start:]), ""
+}
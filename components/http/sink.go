@@ -0,0 +1,310 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	stdhttp "net/http"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+type NewWriterArgs[T any] struct {
+	// Client does the requests. On nil, defaults to http.DefaultClient.
+	Client *stdhttp.Client
+	// URL is where values are sent. On "", the func returns core.WriterImpl[T].
+	URL string
+	// Method is the request method. On "", defaults to POST.
+	Method string
+	// Header is added to all requests, e.g for auth.
+	Header stdhttp.Header
+	// Encoder encodes values into request bodies. On nil, defaults to
+	// json.NewEncoder.
+	Encoder func(io.Writer) core.Encoder
+	// ContentType is the Content-Type of requests. On "", defaults to
+	// "application/json".
+	ContentType string
+	// Gzip makes request bodies gzip compressed.
+	Gzip bool
+	// Retries is the max number of times a request is retried, see NewWriter.
+	// On <= 0, requests are not retried.
+	Retries int
+	// Backoff is the delay before the first retry, it is doubled for each
+	// subsequent retry. On <= 0, defaults to 100ms.
+	Backoff time.Duration
+	// MaxRetryAfter caps the delay given by Retry-After headers. On <= 0,
+	// defaults to 1m.
+	MaxRetryAfter time.Duration
+	// IdempotencyHeader is the name of the idempotency key header. On "",
+	// defaults to "Idempotency-Key".
+	IdempotencyHeader string
+}
+
+// NewWriter returns a Writer which sends each value in a request to args.URL,
+// with the value encoded by args.Encoder as the body. This is useful for e.g
+// webhooks, see NewBatchWriter for ingestion APIs which accept batches.
+//
+// Responses with a status outside of 2xx give a *StatusError. Requests are
+// retried up to args.Retries times on network errs and on retryable statuses
+// (see StatusError.Retryable), with exponential backoff, or as long as the
+// Retry-After header says (up to args.MaxRetryAfter). Other errs, e.g from an
+// invalid args.URL, are not retried. Each value gets a random idempotency key header,
+// which is the same for all retries, such that the receiver may deduplicate.
+//
+// Example:
+//
+//	w := NewWriter(
+//	    NewWriterArgs[event]{
+//	        URL:     "https://hooks.example.com/events",
+//	        Header:  stdhttp.Header{"Authorization": {"Bearer ..."}},
+//	        Retries: 3,
+//	    },
+//	)
+func NewWriter[T any](args NewWriterArgs[T]) core.Writer[T] {
+	if args.URL == "" {
+		return core.WriterImpl[T]{}
+	}
+
+	s := newSender(args)
+	return core.WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) error {
+			return s.send(ctx, func(e core.Encoder) error { return e.Encode(val) })
+		},
+	}
+}
+
+type NewBatchWriterArgs[T any] struct {
+	// Client does the requests. On nil, defaults to http.DefaultClient.
+	Client *stdhttp.Client
+	// URL is where batches are sent. On "", the func returns
+	// core.WriterImpl[[]T].
+	URL string
+	// Method is the request method. On "", defaults to POST.
+	Method string
+	// Header is added to all requests, e.g for auth.
+	Header stdhttp.Header
+	// Encoder encodes values into request bodies. On nil, defaults to
+	// json.NewEncoder.
+	Encoder func(io.Writer) core.Encoder
+	// NDJSON makes each value of a batch be encoded separately, which gives
+	// newline delimited JSON with the default Encoder. If false, the batch is
+	// encoded as a single value, which gives a JSON array.
+	NDJSON bool
+	// ContentType is the Content-Type of requests. On "", defaults to
+	// "application/json", or "application/x-ndjson" if NDJSON is true.
+	ContentType string
+	// Gzip makes request bodies gzip compressed.
+	Gzip bool
+	// Retries is the max number of times a request is retried, see NewWriter.
+	// On <= 0, requests are not retried.
+	Retries int
+	// Backoff is the delay before the first retry, it is doubled for each
+	// subsequent retry. On <= 0, defaults to 100ms.
+	Backoff time.Duration
+	// MaxRetryAfter caps the delay given by Retry-After headers. On <= 0,
+	// defaults to 1m.
+	MaxRetryAfter time.Duration
+	// IdempotencyHeader is the name of the idempotency key header. On "",
+	// defaults to "Idempotency-Key".
+	IdempotencyHeader string
+}
+
+// NewBatchWriter is equivalent to NewWriter, except that it sends a batch of
+// values per request, either as a JSON array or as NDJSON (see
+// NewBatchWriterArgs.NDJSON). Empty batches are ignored. It pairs well with
+// core.NewWriterWithBatching.
+//
+// Example:
+//
+//	w := NewBatchWriter(
+//	    NewBatchWriterArgs[event]{
+//	        URL:     "https://ingest.example.com/events",
+//	        NDJSON:  true,
+//	        Gzip:    true,
+//	        Retries: 5,
+//	    },
+//	)
+//
+//	bw := core.NewWriterWithBatching[event](w, 1000)
+func NewBatchWriter[T any](args NewBatchWriterArgs[T]) core.Writer[[]T] {
+	if args.URL == "" {
+		return core.WriterImpl[[]T]{}
+	}
+	if args.ContentType == "" && args.NDJSON {
+		args.ContentType = "application/x-ndjson"
+	}
+
+	s := newSender(NewWriterArgs[T]{
+		Client:            args.Client,
+		URL:               args.URL,
+		Method:            args.Method,
+		Header:            args.Header,
+		Encoder:           args.Encoder,
+		ContentType:       args.ContentType,
+		Gzip:              args.Gzip,
+		Retries:           args.Retries,
+		Backoff:           args.Backoff,
+		MaxRetryAfter:     args.MaxRetryAfter,
+		IdempotencyHeader: args.IdempotencyHeader,
+	})
+
+	return core.WriterImpl[[]T]{
+		Impl: func(ctx context.Context, batch []T) error {
+			if len(batch) == 0 {
+				return nil
+			}
+
+			return s.send(ctx, func(e core.Encoder) error {
+				if !args.NDJSON {
+					return e.Encode(batch)
+				}
+
+				for _, v := range batch {
+					if err := e.Encode(v); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+		},
+	}
+}
+
+// sender contains the logic shared by NewWriter and NewBatchWriter.
+type sender[T any] struct {
+	args NewWriterArgs[T]
+}
+
+func newSender[T any](args NewWriterArgs[T]) sender[T] {
+	if args.Client == nil {
+		args.Client = stdhttp.DefaultClient
+	}
+	if args.Method == "" {
+		args.Method = stdhttp.MethodPost
+	}
+	if args.Encoder == nil {
+		args.Encoder = func(w io.Writer) core.Encoder { return json.NewEncoder(w) }
+	}
+	if args.ContentType == "" {
+		args.ContentType = "application/json"
+	}
+	if args.Backoff <= 0 {
+		args.Backoff = time.Millisecond * 100
+	}
+	if args.MaxRetryAfter <= 0 {
+		args.MaxRetryAfter = time.Minute
+	}
+	if args.IdempotencyHeader == "" {
+		args.IdempotencyHeader = "Idempotency-Key"
+	}
+
+	return sender[T]{args: args}
+}
+
+// body encodes the request body with 'encode', compressing it if configured.
+func (s sender[T]) body(encode func(core.Encoder) error) ([]byte, error) {
+	b := bytes.NewBuffer(nil)
+	if !s.args.Gzip {
+		err := encode(s.args.Encoder(b))
+		return b.Bytes(), err
+	}
+
+	gw := gzip.NewWriter(b)
+	if err := encode(s.args.Encoder(gw)); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (s sender[T]) send(ctx context.Context, encode func(core.Encoder) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	body, err := s.body(encode)
+	if err != nil {
+		return err
+	}
+
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return err
+	}
+
+	backoff := s.args.Backoff
+	for attempt := 0; ; attempt++ {
+		retryable := false
+		retryable, err = s.do(ctx, body, key)
+		if !retryable || attempt >= s.args.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		delay := backoff
+		backoff *= 2
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			delay = min(statusErr.RetryAfter, s.args.MaxRetryAfter)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// do sends a single request. The bool reports whether the err may go away if
+// the request is retried, i.e for network errs and retryable statuses.
+func (s sender[T]) do(ctx context.Context, body []byte, key string) (bool, error) {
+	req, err := stdhttp.NewRequestWithContext(ctx, s.args.Method, s.args.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	for k, vs := range s.args.Header {
+		req.Header[k] = append([]string(nil), vs...)
+	}
+
+	req.Header.Set("Content-Type", s.args.ContentType)
+	req.Header.Set(s.args.IdempotencyHeader, key)
+	if s.args.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := s.args.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	if err = checkStatus(resp); err != nil {
+		return err.(*StatusError).Retryable(), err
+	}
+
+	// Delivered, so errs from here on would only cause duplicates.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+	return false, nil
+}
+
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,263 @@
+package http
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type tfRequest struct {
+	header stdhttp.Header
+	body   string
+}
+
+// tfNewSinkServer records requests and responds with the given statuses in
+// order, after which it responds with 200.
+func tfNewSinkServer(statuses ...int) (*httptest.Server, func() []tfRequest) {
+	mx := sync.Mutex{}
+	reqs := make([]tfRequest, 0, 8)
+
+	srv := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			body, _ = gzip.NewReader(r.Body)
+		}
+
+		b, _ := io.ReadAll(body)
+
+		mx.Lock()
+		reqs = append(reqs, tfRequest{header: r.Header, body: string(b)})
+		n := len(reqs)
+		mx.Unlock()
+
+		if n <= len(statuses) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statuses[n-1])
+		}
+	}))
+
+	return srv, func() []tfRequest {
+		mx.Lock()
+		defer mx.Unlock()
+
+		return append([]tfRequest(nil), reqs...)
+	}
+}
+
+type tfRoundTripper func(*stdhttp.Request) (*stdhttp.Response, error)
+
+func (f tfRoundTripper) RoundTrip(r *stdhttp.Request) (*stdhttp.Response, error) {
+	return f(r)
+}
+
+type tfErrCloser struct{ io.Reader }
+
+func (tfErrCloser) Close() error { return io.ErrUnexpectedEOF }
+
+// -----------------------------------------------------------------------------
+// Tests: NewWriter.
+// -----------------------------------------------------------------------------
+
+func TestNewWriterIdeal(t *testing.T) {
+	srv, reqs := tfNewSinkServer()
+	defer srv.Close()
+
+	w := NewWriter(
+		NewWriterArgs[int]{
+			URL:    srv.URL,
+			Header: stdhttp.Header{"Authorization": {"test"}},
+		},
+	)
+
+	err := w.Write(context.Background(), 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	have := reqs()
+	assertEq("len", 1, len(have), func(s string) { t.Fatal(s) })
+	assertEq("body", "1\n", have[0].body, func(s string) { t.Fatal(s) })
+	assertEq("auth", "test", have[0].header.Get("Authorization"), func(s string) { t.Fatal(s) })
+	assertEq("type", "application/json", have[0].header.Get("Content-Type"), func(s string) { t.Fatal(s) })
+
+	if len(have[0].header.Get("Idempotency-Key")) != 32 {
+		t.Fatalf("unexpected idempotency key: %v", have[0].header.Get("Idempotency-Key"))
+	}
+}
+
+func TestNewWriterWithRetries(t *testing.T) {
+	srv, reqs := tfNewSinkServer(stdhttp.StatusServiceUnavailable, stdhttp.StatusTooManyRequests)
+	defer srv.Close()
+
+	w := NewWriter(NewWriterArgs[int]{URL: srv.URL, Retries: 2, Backoff: time.Millisecond})
+
+	err := w.Write(context.Background(), 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	have := reqs()
+	assertEq("len", 3, len(have), func(s string) { t.Fatal(s) })
+
+	key := have[0].header.Get("Idempotency-Key")
+	for _, req := range have {
+		assertEq("key", key, req.header.Get("Idempotency-Key"), func(s string) { t.Fatal(s) })
+	}
+
+	// New key for the next value.
+	w.Write(context.Background(), 2)
+	if reqs()[3].header.Get("Idempotency-Key") == key {
+		t.Fatal("expected a new idempotency key")
+	}
+}
+
+func TestNewWriterWithTerminalStatus(t *testing.T) {
+	srv, reqs := tfNewSinkServer(stdhttp.StatusBadRequest)
+	defer srv.Close()
+
+	w := NewWriter(NewWriterArgs[int]{URL: srv.URL, Retries: 2, Backoff: time.Millisecond})
+
+	err := w.Write(context.Background(), 1)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != stdhttp.StatusBadRequest {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	assertEq("len", 1, len(reqs()), func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithRetriesExhausted(t *testing.T) {
+	srv, reqs := tfNewSinkServer(500, 500, 500)
+	defer srv.Close()
+
+	w := NewWriter(NewWriterArgs[int]{URL: srv.URL, Retries: 1, Backoff: time.Millisecond})
+
+	var statusErr *StatusError
+	if err := w.Write(context.Background(), 1); !errors.As(err, &statusErr) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	assertEq("len", 2, len(reqs()), func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithBadMethod(t *testing.T) {
+	w := NewWriter(NewWriterArgs[int]{URL: "http://localhost", Method: "BAD METHOD", Retries: 3, Backoff: time.Hour})
+
+	// Would hang on the backoff if it was retried.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := w.Write(ctx, 1)
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestNewWriterWithBodyCloseErr(t *testing.T) {
+	n := 0
+	client := &stdhttp.Client{
+		Transport: tfRoundTripper(func(r *stdhttp.Request) (*stdhttp.Response, error) {
+			n++
+			return &stdhttp.Response{StatusCode: 200, Body: tfErrCloser{strings.NewReader("")}}, nil
+		}),
+	}
+
+	w := NewWriter(NewWriterArgs[int]{Client: client, URL: "http://localhost", Retries: 2})
+
+	err := w.Write(context.Background(), 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("n", 1, n, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithMaxRetryAfter(t *testing.T) {
+	n := 0
+	client := &stdhttp.Client{
+		Transport: tfRoundTripper(func(r *stdhttp.Request) (*stdhttp.Response, error) {
+			n++
+			if n > 1 {
+				return &stdhttp.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+
+			h := stdhttp.Header{"Retry-After": {"3600"}}
+			return &stdhttp.Response{StatusCode: 503, Header: h, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}),
+	}
+
+	w := NewWriter(
+		NewWriterArgs[int]{
+			Client:        client,
+			URL:           "http://localhost",
+			Retries:       1,
+			MaxRetryAfter: time.Millisecond,
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := w.Write(ctx, 1)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("n", 2, n, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithEmptyURL(t *testing.T) {
+	err := NewWriter(NewWriterArgs[int]{}).Write(context.Background(), 1)
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestRetryAfter(t *testing.T) {
+	assertEq("secs", time.Second*2, retryAfter("2"), func(s string) { t.Fatal(s) })
+	assertEq("invalid", time.Duration(0), retryAfter("x"), func(s string) { t.Fatal(s) })
+
+	d := retryAfter(time.Now().Add(time.Hour).UTC().Format(stdhttp.TimeFormat))
+	if d < time.Minute*59 || d > time.Hour {
+		t.Fatalf("unexpected retry after: %v", d)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewBatchWriter.
+// -----------------------------------------------------------------------------
+
+func TestNewBatchWriterIdeal(t *testing.T) {
+	srv, reqs := tfNewSinkServer()
+	defer srv.Close()
+
+	w := NewBatchWriter(NewBatchWriterArgs[int]{URL: srv.URL})
+
+	w.Write(context.Background(), []int{1, 2})
+	w.Write(context.Background(), nil)
+
+	have := reqs()
+	assertEq("len", 1, len(have), func(s string) { t.Fatal(s) })
+	assertEq("body", "[1,2]\n", have[0].body, func(s string) { t.Fatal(s) })
+}
+
+func TestNewBatchWriterWithNDJSONAndGzip(t *testing.T) {
+	srv, reqs := tfNewSinkServer()
+	defer srv.Close()
+
+	w := NewBatchWriter(NewBatchWriterArgs[int]{URL: srv.URL, NDJSON: true, Gzip: true})
+
+	err := w.Write(context.Background(), []int{1, 2})
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	have := reqs()
+	assertEq("type", "application/x-ndjson", have[0].header.Get("Content-Type"), func(s string) { t.Fatal(s) })
+
+	vals := []int{}
+	sc := bufio.NewScanner(strings.NewReader(have[0].body))
+	for sc.Scan() {
+		v := 0
+		json.Unmarshal(sc.Bytes(), &v)
+		vals = append(vals, v)
+	}
+
+	assertEq("vals", []int{1, 2}, vals, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	stdhttp "net/http"
+
+	"github.com/crunchypi/gtl/components/page"
+	"github.com/crunchypi/gtl/core"
+)
+
+// Envelope is a common JSON page format, where values are wrapped along with
+// a link to the next page, see NewEnvelopeReader.
+type Envelope[T any] struct {
+	Data []T    `json:"data"`
+	Next string `json:"next"`
+}
+
+// Page returns the values and next link of the envelope.
+func (e Envelope[T]) Page() ([]T, string) {
+	return e.Data, e.Next
+}
+
+type NewLinkReaderArgs[T any] struct {
+	// Client does the requests. On nil, defaults to http.DefaultClient.
+	Client *stdhttp.Client
+	// URL is the first page. On "", the func returns core.ReaderImpl[T].
+	URL string
+	// Request is optional, it makes the GET request for each page, e.g for
+	// adding auth headers. On nil, a plain GET request with an "Accept:
+	// application/json" header is used.
+	Request func(ctx context.Context, url string) (*stdhttp.Request, error)
+	// Decoder decodes the body of each page into []T. On nil, defaults to
+	// json.NewDecoder.
+	Decoder func(io.Reader) core.Decoder
+}
+
+// NewLinkReader returns a Reader of values from a REST API which paginates
+// with RFC 5988 Link headers, e.g:
+//
+//	Link: <https://api.example.com/items?page=2>; rel="next"
+//
+// The body of each page is decoded as []T, after which the rel="next" link is
+// followed (relative links are resolved against the page URL), until there
+// is no such link, after which io.EOF is returned. Responses with a status
+// outside of 2xx give a *StatusError, and empty bodies give
+// io.ErrUnexpectedEOF. See page.NewCursorReader for details on how errs are
+// handled.
+//
+// Example:
+//
+//	r := NewLinkReader(
+//	    NewLinkReaderArgs[user]{
+//	        URL: "https://api.example.com/users?per_page=100",
+//	    },
+//	)
+func NewLinkReader[T any](args NewLinkReaderArgs[T]) core.Reader[T] {
+	if args.URL == "" {
+		return core.ReaderImpl[T]{}
+	}
+
+	f := newPageFetcher(args.Client, args.Request, args.Decoder)
+	return core.NewReaderWithUnbatching(
+		page.NewCursorReader(
+			page.NewCursorReaderArgs[T, string]{
+				Start: args.URL,
+				Fetch: func(ctx context.Context, u string) (vals []T, next string, err error) {
+					resp, err := f.fetch(ctx, u, &vals)
+					if err != nil {
+						return nil, "", err
+					}
+
+					if next = linkNext(resp.Header); next != "" {
+						next, err = resolve(resp.Request.URL, next)
+					}
+
+					return vals, next, err
+				},
+			},
+		),
+	)
+}
+
+type NewEnvelopeReaderArgs[T any, E any] struct {
+	// Client does the requests. On nil, defaults to http.DefaultClient.
+	Client *stdhttp.Client
+	// URL is the first page. On "", the func returns core.ReaderImpl[T].
+	URL string
+	// Request is optional, it makes the GET request for each page, e.g for
+	// adding auth headers. On nil, a plain GET request with an "Accept:
+	// application/json" header is used.
+	Request func(ctx context.Context, url string) (*stdhttp.Request, error)
+	// Decoder decodes the body of each page into E. On nil, defaults to
+	// json.NewDecoder.
+	Decoder func(io.Reader) core.Decoder
+	// Unwrap returns the values and the next link of a decoded page. On nil,
+	// E must have a "Page() ([]T, string)" method (such as Envelope), else
+	// the func returns core.ReaderImpl[T].
+	Unwrap func(E) ([]T, string)
+}
+
+// NewEnvelopeReader returns a Reader of values from a REST API which wraps
+// pages in JSON envelopes, such as Envelope:
+//
+//	{"data": [...], "next": "https://api.example.com/items?cursor=abc"}
+//
+// The body of each page is decoded as E and unwrapped with args.Unwrap, after
+// which the next link is followed (relative links are resolved against the
+// page URL), until it is "", after which io.EOF is returned. Responses with a
+// status outside of 2xx give a *StatusError, and empty bodies give
+// io.ErrUnexpectedEOF. See page.NewCursorReader for details on how errs are
+// handled.
+//
+// Example:
+//
+//	r := NewEnvelopeReader(
+//	    NewEnvelopeReaderArgs[user, Envelope[user]]{
+//	        URL: "https://api.example.com/users",
+//	    },
+//	)
+func NewEnvelopeReader[T any, E any](args NewEnvelopeReaderArgs[T, E]) core.Reader[T] {
+	if args.Unwrap == nil {
+		if _, ok := any(*new(E)).(interface{ Page() ([]T, string) }); ok {
+			args.Unwrap = func(e E) ([]T, string) {
+				return any(e).(interface{ Page() ([]T, string) }).Page()
+			}
+		}
+	}
+	if args.URL == "" || args.Unwrap == nil {
+		return core.ReaderImpl[T]{}
+	}
+
+	f := newPageFetcher(args.Client, args.Request, args.Decoder)
+	return core.NewReaderWithUnbatching(
+		page.NewCursorReader(
+			page.NewCursorReaderArgs[T, string]{
+				Start: args.URL,
+				Fetch: func(ctx context.Context, u string) (vals []T, next string, err error) {
+					var e E
+					resp, err := f.fetch(ctx, u, &e)
+					if err != nil {
+						return nil, "", err
+					}
+
+					vals, next = args.Unwrap(e)
+					if next != "" {
+						next, err = resolve(resp.Request.URL, next)
+					}
+
+					return vals, next, err
+				},
+			},
+		),
+	)
+}
+
+// pageFetcher contains the logic shared by NewLinkReader and NewEnvelopeReader.
+type pageFetcher struct {
+	client  *stdhttp.Client
+	request func(ctx context.Context, url string) (*stdhttp.Request, error)
+	decoder func(io.Reader) core.Decoder
+}
+
+func newPageFetcher(
+	client *stdhttp.Client,
+	request func(ctx context.Context, url string) (*stdhttp.Request, error),
+	decoder func(io.Reader) core.Decoder,
+) pageFetcher {
+	if client == nil {
+		client = stdhttp.DefaultClient
+	}
+	if request == nil {
+		request = newGetRequest
+	}
+	if decoder == nil {
+		decoder = func(r io.Reader) core.Decoder { return json.NewDecoder(r) }
+	}
+
+	return pageFetcher{client: client, request: request, decoder: decoder}
+}
+
+// fetch gets 'u' and decodes the body into 'v'. The body of the returned
+// response is closed. An empty body gives io.ErrUnexpectedEOF, since io.EOF
+// would be mistaken for the end of the stream.
+func (f pageFetcher) fetch(ctx context.Context, u string, v any) (*stdhttp.Response, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := f.request(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if err = f.decoder(resp.Body).Decode(v); err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
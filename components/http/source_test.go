@@ -0,0 +1,217 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func assertEq[T any](subject string, want T, have T, f func(string)) {
+	if f == nil {
+		return
+	}
+
+	ab, _ := json.Marshal(want)
+	bb, _ := json.Marshal(have)
+
+	as := string(ab)
+	bs := string(bb)
+
+	if as == bs {
+		return
+	}
+
+	s := "unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n"
+	f(fmt.Sprintf(s, subject, as, bs))
+}
+
+func tfReadAll[T any](ctx context.Context, r core.Reader[T]) ([]T, error) {
+	var v T
+	var s = make([]T, 0, 8)
+	var err error
+
+	for v, err = r.Read(ctx); err == nil; v, err = r.Read(ctx) {
+		s = append(s, v)
+	}
+
+	return s, err
+}
+
+// tfPages is pages served by tfNewPageServer.
+var tfPages = [][]int{{1, 2}, {}, {3}}
+
+// tfNewPageServer serves tfPages at /items?page=n, with links to the next page
+// as given by 'link', which is called with the next path (or "" if last).
+func tfNewPageServer(link func(w stdhttp.ResponseWriter, next string, vals []int)) *httptest.Server {
+	return httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if n >= len(tfPages) {
+			w.WriteHeader(stdhttp.StatusNotFound)
+			return
+		}
+
+		next := ""
+		if n+1 < len(tfPages) {
+			next = "/items?page=" + strconv.Itoa(n+1)
+		}
+
+		link(w, next, tfPages[n])
+	}))
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewLinkReader.
+// -----------------------------------------------------------------------------
+
+func TestNewLinkReaderIdeal(t *testing.T) {
+	srv := tfNewPageServer(func(w stdhttp.ResponseWriter, next string, vals []int) {
+		if next != "" {
+			w.Header().Add("Link", `<https://example.com/prev>; rel="prev"`)
+			w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next last"`, next))
+		}
+
+		json.NewEncoder(w).Encode(vals)
+	})
+	defer srv.Close()
+
+	vals, err := tfReadAll(context.Background(), NewLinkReader(NewLinkReaderArgs[int]{URL: srv.URL + "/items"}))
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2, 3}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewLinkReaderWithStatusErr(t *testing.T) {
+	srv := tfNewPageServer(func(w stdhttp.ResponseWriter, next string, vals []int) {
+		w.WriteHeader(stdhttp.StatusTooManyRequests)
+	})
+	defer srv.Close()
+
+	_, err := NewLinkReader(NewLinkReaderArgs[int]{URL: srv.URL}).Read(context.Background())
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != stdhttp.StatusTooManyRequests {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestNewLinkReaderWithEmptyBody(t *testing.T) {
+	srv := tfNewPageServer(func(w stdhttp.ResponseWriter, next string, vals []int) {
+		w.WriteHeader(stdhttp.StatusOK)
+	})
+	defer srv.Close()
+
+	_, err := NewLinkReader(NewLinkReaderArgs[int]{URL: srv.URL}).Read(context.Background())
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestNewLinkReaderWithRequest(t *testing.T) {
+	srv := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		json.NewEncoder(w).Encode([]string{r.Header.Get("Authorization")})
+	}))
+	defer srv.Close()
+
+	r := NewLinkReader(
+		NewLinkReaderArgs[string]{
+			URL: srv.URL,
+			Request: func(ctx context.Context, url string) (*stdhttp.Request, error) {
+				req, err := stdhttp.NewRequestWithContext(ctx, stdhttp.MethodGet, url, nil)
+				req.Header.Set("Authorization", "test")
+				return req, err
+			},
+		},
+	)
+
+	vals, _ := tfReadAll(context.Background(), r)
+	assertEq("vals", []string{"test"}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewLinkReaderWithEmptyURL(t *testing.T) {
+	_, err := NewLinkReader(NewLinkReaderArgs[int]{}).Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestLinkNext(t *testing.T) {
+	h := stdhttp.Header{}
+	h.Add("Link", `<a>; rel="prev", <b>; title="x"; REL=next`)
+	assertEq("next", "b", linkNext(h), func(s string) { t.Fatal(s) })
+
+	h = stdhttp.Header{}
+	h.Add("Link", `<a>; rel="nextish"`)
+	assertEq("next", "", linkNext(h), func(s string) { t.Fatal(s) })
+}
+
+func TestLinkNextWithSeparatorsInTarget(t *testing.T) {
+	for _, tc := range []struct {
+		link string
+		want string
+	}{
+		{`<https://a.com/x?fields=a,b>; rel="next"`, "https://a.com/x?fields=a,b"},
+		{`<https://a.com/x;v=1>; rel=next`, "https://a.com/x;v=1"},
+		{`<https://a.com/p?a,b>; rel="prev", <https://a.com/n?a,b>; rel="next"`, "https://a.com/n?a,b"},
+		{`<https://a.com/p>; title="x, rel=next; y"; rel="prev"`, ""},
+		{`<https://a.com/p>; rel="prev"`, ""},
+	} {
+		h := stdhttp.Header{"Link": {tc.link}}
+		assertEq("next", tc.want, linkNext(h), func(s string) { t.Fatal(s) })
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewEnvelopeReader.
+// -----------------------------------------------------------------------------
+
+func TestNewEnvelopeReaderIdeal(t *testing.T) {
+	srv := tfNewPageServer(func(w stdhttp.ResponseWriter, next string, vals []int) {
+		json.NewEncoder(w).Encode(Envelope[int]{Data: vals, Next: next})
+	})
+	defer srv.Close()
+
+	r := NewEnvelopeReader(NewEnvelopeReaderArgs[int, Envelope[int]]{URL: srv.URL + "/items"})
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2, 3}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewEnvelopeReaderWithUnwrap(t *testing.T) {
+	type envelope struct {
+		Items []int `json:"items"`
+		Links struct {
+			Next string `json:"next"`
+		} `json:"links"`
+	}
+
+	srv := tfNewPageServer(func(w stdhttp.ResponseWriter, next string, vals []int) {
+		e := envelope{Items: vals}
+		e.Links.Next = next
+		json.NewEncoder(w).Encode(e)
+	})
+	defer srv.Close()
+
+	r := NewEnvelopeReader(
+		NewEnvelopeReaderArgs[int, envelope]{
+			URL:    srv.URL + "/items",
+			Unwrap: func(e envelope) ([]int, string) { return e.Items, e.Links.Next },
+		},
+	)
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2, 3}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewEnvelopeReaderWithNilUnwrap(t *testing.T) {
+	r := NewEnvelopeReader(NewEnvelopeReaderArgs[int, struct{}]{URL: "http://localhost"})
+
+	_, err := r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
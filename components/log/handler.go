@@ -0,0 +1,171 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// SetDefaultLogger replaces the logger used by the constructors of this pkg
+// when the Logger field of their args is nil, e.g with a logger which uses
+// NewHandler. It only affects constructors called after it. Nil is ignored.
+func SetDefaultLogger(l *slog.Logger) {
+	if l != nil {
+		defaultLogger = l
+	}
+}
+
+// LogRecord is a structured form of a slog.Record, see NewHandler.
+type LogRecord struct {
+	Time  time.Time      `json:"time"`
+	Level slog.Level     `json:"level"`
+	Msg   string         `json:"msg"`
+	Attrs map[string]any `json:"attrs"`
+}
+
+type NewHandlerArgs struct {
+	// Writer is where records are written. On nil, all records are
+	// discarded with io.ErrClosedPipe.
+	Writer core.Writer[LogRecord]
+	// Level is the minimum level of records which are handled. On nil,
+	// defaults to slog.LevelInfo.
+	Level slog.Leveler
+}
+
+// NewHandler returns a slog.Handler which converts each slog.Record into a
+// LogRecord and writes it to args.Writer, using the ctx given to the logger
+// (context.Background if it is nil). This makes it possible to treat logs as a
+// stream, e.g batch them with core.NewWriterWithBatching and ship them to
+// files or HTTP.
+//
+// Attrs become LogRecord.Attrs, groups (from WithGroup or slog.Group) become
+// nested maps, errs become their Error() string and slog.LogValuer values
+// are resolved. Handlers derived with WithAttrs and WithGroup share a mutex,
+// such that args.Writer is never called concurrently. Errs from args.Writer are
+// returned from Handle, note that slog.Logger ignores those.
+//
+// Example:
+//
+//	// myBatchedWriter is a core.Writer[[]LogRecord], e.g shipping to HTTP.
+//	w := core.NewWriterWithBatching(myBatchedWriter, 100)
+//
+//	l := slog.New(NewHandler(NewHandlerArgs{Writer: w}))
+//	l.Info("hello", "n", 1) // Written to w as a LogRecord.
+func NewHandler(args NewHandlerArgs) slog.Handler {
+	if args.Writer == nil {
+		args.Writer = core.WriterImpl[LogRecord]{}
+	}
+	if args.Level == nil {
+		args.Level = slog.LevelInfo
+	}
+
+	return &handler{mx: &sync.Mutex{}, w: args.Writer, level: args.Level}
+}
+
+// handlerAttr is an attr given to handler.WithAttrs, along with the groups
+// which were open at that time.
+type handlerAttr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+type handler struct {
+	mx     *sync.Mutex // Shared by all derived handlers.
+	w      core.Writer[LogRecord]
+	level  slog.Leveler
+	groups []string
+	attrs  []handlerAttr
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	m := make(map[string]any, len(h.attrs)+r.NumAttrs())
+	for _, a := range h.attrs {
+		addAttr(m, a.groups, a.attr)
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(m, h.groups, a)
+		return true
+	})
+
+	rec := LogRecord{Time: r.Time, Level: r.Level, Msg: r.Message, Attrs: m}
+
+	h.mx.Lock()
+	defer h.mx.Unlock()
+
+	return h.w.Write(ctx, rec)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	c := *h
+	c.attrs = make([]handlerAttr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(c.attrs, h.attrs)
+	for _, a := range attrs {
+		c.attrs = append(c.attrs, handlerAttr{groups: h.groups, attr: a})
+	}
+
+	return &c
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	c := *h
+	c.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &c
+}
+
+// addAttr adds 'a' to 'm', nested under 'groups'. Empty groups are omitted.
+func addAttr(m map[string]any, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if len(a.Value.Group()) == 0 {
+			return
+		}
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			addAttr(m, groups, ga)
+		}
+
+		return
+	}
+
+	for _, g := range groups {
+		sub, ok := m[g].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			m[g] = sub
+		}
+
+		m = sub
+	}
+
+	v := a.Value.Any()
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+
+	m[a.Key] = v
+}
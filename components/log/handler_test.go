@@ -0,0 +1,94 @@
+package log
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func TestNewHandlerIdeal(t *testing.T) {
+	rw := core.NewReadWriterFrom[LogRecord]()
+	l := slog.New(NewHandler(NewHandlerArgs{Writer: rw, Level: slog.LevelDebug}))
+
+	l = l.With("a", 1).WithGroup("g").With("b", 2)
+	l.Debug("test", "c", tvErr, slog.Group("h", "d", 3), slog.Group("empty"))
+
+	rec, err := rw.Read(nil)
+	tfAssertEq(t, "err", nil, err)
+	tfAssertEq(t, "level", slog.LevelDebug, rec.Level)
+	tfAssertEq(t, "msg", "test", rec.Msg)
+
+	want := map[string]any{
+		"a": 1,
+		"g": map[string]any{"b": 2, "c": tvErr.Error(), "h": map[string]any{"d": 3}},
+	}
+
+	tfAssertEq(t, "attrs", want, rec.Attrs)
+}
+
+func TestNewHandlerWithLevel(t *testing.T) {
+	rw := core.NewReadWriterFrom[LogRecord]()
+	l := slog.New(NewHandler(NewHandlerArgs{Writer: rw}))
+
+	l.Debug("a")
+	l.Info("b")
+
+	rec, _ := rw.Read(nil)
+	tfAssertEq(t, "msg", "b", rec.Msg)
+
+	_, err := rw.Read(nil)
+	tfAssertEq(t, "err", io.EOF, err)
+}
+
+func TestNewHandlerWithNilWriter(t *testing.T) {
+	h := NewHandler(NewHandlerArgs{})
+
+	err := h.Handle(context.Background(), slog.Record{Level: slog.LevelInfo})
+	tfAssertEq(t, "err", io.ErrClosedPipe, err)
+}
+
+func TestSetDefaultLogger(t *testing.T) {
+	prev := defaultLogger
+	defer func() { defaultLogger = prev }()
+
+	rw := core.NewReadWriterFrom[LogRecord]()
+	SetDefaultLogger(slog.New(NewHandler(NewHandlerArgs{Writer: rw})))
+
+	tfReadAll(tvCtx, NewStreamedReader(NewStreamedReaderArgs[int]{Reader: core.NewReaderFrom(1)}))
+
+	rec, err := rw.Read(nil)
+	tfAssertEq(t, "err", nil, err)
+	tfAssertEq(t, "val", 1, rec.Attrs["val"])
+}
+
+func TestNewHandlerWithSlogtest(t *testing.T) {
+	rw := core.NewReadWriterFrom[LogRecord]()
+	h := NewHandler(NewHandlerArgs{Writer: rw})
+
+	results := func() []map[string]any {
+		recs, _ := tfReadAll(nil, rw)
+
+		ms := make([]map[string]any, 0, len(recs))
+		for _, rec := range recs {
+			m := map[string]any{slog.LevelKey: rec.Level, slog.MessageKey: rec.Msg}
+			if !rec.Time.IsZero() {
+				m[slog.TimeKey] = rec.Time
+			}
+			for k, v := range rec.Attrs {
+				m[k] = v
+			}
+
+			ms = append(ms, m)
+		}
+
+		return ms
+	}
+
+	if err := slogtest.TestHandler(h, results); err != nil {
+		t.Fatal(err)
+	}
+}
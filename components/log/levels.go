@@ -0,0 +1,177 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Levels decides the log level of records, depending on the err given by
+// the wrapped Reader or Writer. Fields are slog.Leveler, so either a
+// slog.Level or a *slog.LevelVar may be used. Nil fields fall back to the
+// same field of DefaultLevels, e.g &Levels{Canceled: slog.LevelWarn} only
+// changes the level of cancellations.
+type Levels struct {
+	// Success is used when there is no err.
+	Success slog.Leveler
+	// Canceled is used on errs matching context.Canceled.
+	Canceled slog.Leveler
+	// Deadline is used on errs matching context.DeadlineExceeded.
+	Deadline slog.Leveler
+	// Other is used on all other errs.
+	Other slog.Leveler
+	// End is used for the single record logged at the end of a stream, i.e
+	// on io.EOF for readers and io.ErrClosedPipe for writers. This is only
+	// logged if the LogEnd field of the args is true.
+	End slog.Leveler
+}
+
+// DefaultLevels are the Levels used when the Levels field of args is nil, or
+// when fields of it are nil.
+var DefaultLevels = Levels{
+	Success:  slog.LevelInfo,
+	Canceled: slog.LevelError,
+	Deadline: slog.LevelError,
+	Other:    slog.LevelError,
+	End:      slog.LevelDebug,
+}
+
+func (l Levels) level(err error) slog.Level {
+	switch {
+	case err == nil:
+		return levelOr(l.Success, DefaultLevels.Success)
+	case errors.Is(err, context.Canceled):
+		return levelOr(l.Canceled, DefaultLevels.Canceled)
+	case errors.Is(err, context.DeadlineExceeded):
+		return levelOr(l.Deadline, DefaultLevels.Deadline)
+	default:
+		return levelOr(l.Other, DefaultLevels.Other)
+	}
+}
+
+func (l Levels) end() slog.Level {
+	return levelOr(l.End, DefaultLevels.End)
+}
+
+// levelOr returns the level of 'lv', or of 'fallback' if 'lv' is nil.
+func levelOr(lv, fallback slog.Leveler) slog.Level {
+	if lv == nil {
+		lv = fallback
+	}
+	if lv == nil {
+		return slog.LevelInfo
+	}
+
+	return lv.Level()
+}
+
+// emitter contains the logging logic shared by all constructors in this pkg.
+type emitter struct {
+	logger  *slog.Logger
+	msg     string
+	keys    []string
+	levels  Levels
+	sampler *sampler
+	logEnd  bool
+	ended   atomic.Bool
+	count   atomic.Int64 // Values seen, used for the end record.
+}
+
+func newEmitter(l *slog.Logger, msg string, keys []string, lv *Levels, s *Sampling, logEnd bool) *emitter {
+	if l == nil {
+		l = defaultLogger
+	}
+	if msg == "" {
+		msg = "<unset>"
+	}
+	if lv == nil {
+		lv = &DefaultLevels
+	}
+
+	return &emitter{
+		logger:  l,
+		msg:     msg,
+		keys:    keys,
+		levels:  *lv,
+		sampler: newSampler(s),
+		logEnd:  logEnd,
+	}
+}
+
+func (e *emitter) ctxGroup(ctx context.Context) slog.Attr {
+	attrs := make([]any, 0, len(e.keys))
+	for _, k := range e.keys {
+		var v any
+		if ctx != nil {
+			v = ctx.Value(k)
+		}
+
+		attrs = append(attrs, slog.Any(k, v))
+	}
+
+	return slog.Group("ctx", attrs...)
+}
+
+// emit logs a record for a single read or write, 'n' is the number of values
+// it contained and 'val' is either a "val" or "len" attribute.
+func (e *emitter) emit(ctx context.Context, err error, n int, val func() slog.Attr) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err == nil {
+		e.count.Add(int64(n))
+	}
+
+	ok, suppressed := e.sampler.allow(err != nil)
+	e.suppressed(ctx, suppressed)
+	if !ok {
+		return
+	}
+
+	level := e.levels.level(err)
+	if !e.logger.Enabled(ctx, level) {
+		return
+	}
+
+	e.logger.LogAttrs(
+		ctx,
+		level,
+		e.msg,
+		slog.Any("err", err),
+		val(),
+		e.ctxGroup(ctx),
+	)
+}
+
+// suppressed logs the number of records suppressed by sampling, if any.
+func (e *emitter) suppressed(ctx context.Context, n int) {
+	if n > 0 {
+		e.logger.LogAttrs(ctx, slog.LevelInfo, e.msg, slog.Int("suppressed", n))
+	}
+}
+
+// end logs the end of a stream once, i.e records which are still suppressed
+// and (if enabled) the end record.
+func (e *emitter) end(ctx context.Context, err error) {
+	if e.ended.Swap(true) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	e.suppressed(ctx, e.sampler.flush())
+	if !e.logEnd {
+		return
+	}
+
+	e.logger.LogAttrs(
+		ctx,
+		e.levels.end(),
+		e.msg,
+		slog.Any("err", err),
+		slog.Int64("count", e.count.Load()),
+		e.ctxGroup(ctx),
+	)
+}
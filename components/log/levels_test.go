@@ -0,0 +1,105 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func tfAssertEq(t *testing.T, subject string, want, have any) {
+	if fmt.Sprint(want) != fmt.Sprint(have) {
+		t.Fatalf("unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n", subject, want, have)
+	}
+}
+
+func TestLevelsLevel(t *testing.T) {
+	lv := Levels{Success: slog.Level(1), Canceled: slog.Level(2), Deadline: slog.Level(3), Other: slog.Level(4)}
+
+	tfAssertEq(t, "nil", slog.Level(1), lv.level(nil))
+	tfAssertEq(t, "canceled", slog.Level(2), lv.level(context.Canceled))
+	tfAssertEq(t, "deadline", slog.Level(3), lv.level(context.DeadlineExceeded))
+	tfAssertEq(t, "other", slog.Level(4), lv.level(tvErr))
+}
+
+func TestLevelsLevelWithNilFields(t *testing.T) {
+	v := &slog.LevelVar{}
+	v.Set(slog.LevelWarn)
+	lv := Levels{Canceled: v}
+
+	tfAssertEq(t, "nil", slog.LevelInfo, lv.level(nil))
+	tfAssertEq(t, "canceled", slog.LevelWarn, lv.level(context.Canceled))
+	tfAssertEq(t, "deadline", slog.LevelError, lv.level(context.DeadlineExceeded))
+	tfAssertEq(t, "other", slog.LevelError, lv.level(tvErr))
+	tfAssertEq(t, "end", slog.LevelDebug, lv.end())
+}
+
+func TestNewStreamedReaderWithLevels(t *testing.T) {
+	l, b := tfNewBufLogger()
+
+	r := NewStreamedReader(
+		NewStreamedReaderArgs[int]{
+			Reader: core.ReaderImpl[int]{
+				Impl: func(ctx context.Context) (int, error) { return 0, context.Canceled },
+			},
+			Logger: l,
+			Levels: &Levels{Canceled: slog.LevelWarn},
+		},
+	)
+
+	r.Read(tvCtx)
+
+	recs := tfRecords(b)
+	tfAssertEq(t, "len", 1, len(recs))
+	tfAssertEq(t, "level", "WARN", recs[0]["level"])
+	tfAssertEq(t, "err", "context canceled", recs[0]["err"])
+}
+
+func TestNewBatchedReaderWithLogEnd(t *testing.T) {
+	l, b := tfNewBufLogger()
+
+	r := NewBatchedReader(
+		NewBatchedReaderArgs[int]{
+			Reader:  core.NewReaderFrom([]int{1, 2}, []int{3}),
+			Logger:  l,
+			Msg:     "test",
+			CtxKeys: []string{tvCtxKey},
+			LogEnd:  true,
+		},
+	)
+
+	tfReadAll(tvCtx, r)
+	r.Read(tvCtx) // Should not log the end again.
+
+	recs := tfRecords(b)
+	tfAssertEq(t, "len", 3, len(recs))
+	tfAssertEq(t, "level", "INFO", recs[0]["level"])
+	tfAssertEq(t, "len", 2.0, recs[0]["len"])
+	tfAssertEq(t, "ctx", map[string]any{tvCtxKey: tvCtxVal}, recs[0]["ctx"])
+	tfAssertEq(t, "level", "DEBUG", recs[2]["level"])
+	tfAssertEq(t, "err", io.EOF.Error(), recs[2]["err"])
+	tfAssertEq(t, "count", 3.0, recs[2]["count"])
+}
+
+func TestNewStreamedWriterWithLogEnd(t *testing.T) {
+	l, b := tfNewBufLogger()
+
+	w := NewStreamedWriter(
+		NewStreamedWriterArgs[int]{
+			Writer: core.NewWriterWithTake[int](core.WriterImpl[int]{
+				Impl: func(ctx context.Context, v int) error { return nil },
+			}, 2),
+			Logger: l,
+			LogEnd: true,
+		},
+	)
+
+	tfWriteSlice(nil, []int{1, 2, 3, 4}, w)
+
+	recs := tfRecords(b)
+	tfAssertEq(t, "len", 3, len(recs))
+	tfAssertEq(t, "count", 2.0, recs[2]["count"])
+}
@@ -16,26 +16,15 @@ func init() {
 	defaultLogger = slog.New(h)
 }
 
-func ctxValues(ctx context.Context, keys []string) map[string]any {
-	m := make(map[string]any, len(keys))
-
-	if ctx == nil {
-		return m
-	}
-
-	for _, k := range keys {
-		m[k] = ctx.Value(k)
-	}
-
-	return m
-}
-
 type NewStreamedReaderArgs[T any] struct {
-	Reader  core.Reader[T] // On nil, the func returns core.ReaderImpl[T]
-	Logger  *slog.Logger   // On nil, will use a default logger.
-	Msg     string         // On "" , will set the log "msg" to "<unset>"
-	Fmt     func(T) any    // On nil, will set the log "val" to the value of T.
-	CtxKeys []string       // On nil, will set the log "ctx" to nil.
+	Reader   core.Reader[T] // On nil, the func returns core.ReaderImpl[T]
+	Logger   *slog.Logger   // On nil, will use a default logger.
+	Msg      string         // On "" , will set the log "msg" to "<unset>"
+	Fmt      func(T) any    // On nil, will set the log "val" to the value of T.
+	CtxKeys  []string       // On nil, the log "ctx" is omitted.
+	Sampling *Sampling      // On nil, all records are logged.
+	Levels   *Levels        // On nil, will use DefaultLevels.
+	LogEnd   bool           // If true, io.EOF is logged once with "count".
 }
 
 // NewStreamedReader returns a reader which wraps args.Reader with logging.
@@ -46,7 +35,7 @@ type NewStreamedReaderArgs[T any] struct {
 //
 // Logging format details:
 //   - "time": Format depends on args.Logger. Default is RFC3999.
-//   - "level": Normally INFO, may be ERROR on errs other than io.EOF.
+//   - "level": Decided by args.Levels, see Levels.
 //   - "msg": Set to args.Msg. Will be "<unset>" if not set.
 //   - "err": Set to read errs.
 //   - "val": Values from args.Reader, formatted by args.Fmt.
@@ -58,44 +47,33 @@ func NewStreamedReader[T any](args NewStreamedReaderArgs[T]) core.Reader[T] {
 	if args.Reader == nil {
 		return core.ReaderImpl[T]{}
 	}
-	if args.Logger == nil {
-		args.Logger = defaultLogger
-	}
-	if args.Msg == "" {
-		args.Msg = "<unset>"
-	}
 	if args.Fmt == nil {
 		args.Fmt = func(v T) any { return v }
 	}
 
+	e := newEmitter(args.Logger, args.Msg, args.CtxKeys, args.Levels, args.Sampling, args.LogEnd)
 	return core.ReaderImpl[T]{
 		Impl: func(ctx context.Context) (val T, err error) {
 			val, err = args.Reader.Read(ctx)
 			if err == io.EOF {
+				e.end(ctx, err)
 				return
 			}
 
-			l := args.Logger
-			l = l.With("err", err)
-			l = l.With("val", args.Fmt(val))
-			l = l.With("ctx", ctxValues(ctx, args.CtxKeys))
-
-			f := l.Info
-			if err != nil {
-				f = l.Error
-			}
-
-			f(args.Msg)
+			e.emit(ctx, err, 1, func() slog.Attr { return slog.Any("val", args.Fmt(val)) })
 			return val, err
 		},
 	}
 }
 
 type NewBatchedReaderArgs[T any] struct {
-	Reader  core.Reader[[]T] // On nil, the func returns core.ReaderImpl[[]T]
-	Logger  *slog.Logger     // On nil, will use a default logger.
-	Msg     string           // On "" , will set the log "msg" to "<unset>"
-	CtxKeys []string         // On nil, will set the log "ctx" to nil.
+	Reader   core.Reader[[]T] // On nil, the func returns core.ReaderImpl[[]T]
+	Logger   *slog.Logger     // On nil, will use a default logger.
+	Msg      string           // On "" , will set the log "msg" to "<unset>"
+	CtxKeys  []string         // On nil, the log "ctx" is omitted.
+	Sampling *Sampling        // On nil, all records are logged.
+	Levels   *Levels          // On nil, will use DefaultLevels.
+	LogEnd   bool             // If true, io.EOF is logged once with "count".
 }
 
 // NewBatchedReader returns a reader which wraps args.Reader with logging.
@@ -106,7 +84,7 @@ type NewBatchedReaderArgs[T any] struct {
 //
 // Logging format details:
 //   - "time": Format depends on args.Logger. Default is RFC3999.
-//   - "level": Normally INFO, may be ERROR on errs other than io.EOF.
+//   - "level": Decided by args.Levels, see Levels.
 //   - "msg": Set to args.Msg. Will be "<unset>" if not set.
 //   - "err": Set to read errs.
 //   - "len": The len of values read from args.Reader.
@@ -118,42 +96,31 @@ func NewBatchedReader[T any](args NewBatchedReaderArgs[T]) core.Reader[[]T] {
 	if args.Reader == nil {
 		return core.ReaderImpl[[]T]{}
 	}
-	if args.Logger == nil {
-		args.Logger = defaultLogger
-	}
-	if args.Msg == "" {
-		args.Msg = "<unset>"
-	}
 
+	e := newEmitter(args.Logger, args.Msg, args.CtxKeys, args.Levels, args.Sampling, args.LogEnd)
 	return core.ReaderImpl[[]T]{
 		Impl: func(ctx context.Context) (s []T, err error) {
 			s, err = args.Reader.Read(ctx)
 			if err == io.EOF {
+				e.end(ctx, err)
 				return
 			}
 
-			l := args.Logger
-			l = l.With("err", err)
-			l = l.With("len", len(s))
-			l = l.With("ctx", ctxValues(ctx, args.CtxKeys))
-
-			f := l.Info
-			if err != nil {
-				f = l.Error
-			}
-
-			f(args.Msg)
+			e.emit(ctx, err, len(s), func() slog.Attr { return slog.Int("len", len(s)) })
 			return s, err
 		},
 	}
 }
 
 type NewStreamedWriterArgs[T any] struct {
-	Writer  core.Writer[T] // On nil, the func returns core.WriterImpl[T]
-	Logger  *slog.Logger   // On nil, will use a default logger.
-	Msg     string         // On "" , will set the log "msg" to "<unset>"
-	Fmt     func(T) any    // On nil, will set the log "val" to the value of T.
-	CtxKeys []string       // On nil, will set the log "ctx" to nil.
+	Writer   core.Writer[T] // On nil, the func returns core.WriterImpl[T]
+	Logger   *slog.Logger   // On nil, will use a default logger.
+	Msg      string         // On "" , will set the log "msg" to "<unset>"
+	Fmt      func(T) any    // On nil, will set the log "val" to the value of T.
+	CtxKeys  []string       // On nil, the log "ctx" is omitted.
+	Sampling *Sampling      // On nil, all records are logged.
+	Levels   *Levels        // On nil, will use DefaultLevels.
+	LogEnd   bool           // If true, io.ErrClosedPipe is logged once with "count".
 }
 
 // NewStreamedWriter returns a writer which accepts values and passes them to
@@ -165,7 +132,7 @@ type NewStreamedWriterArgs[T any] struct {
 //
 // Logging format details:
 //   - "time": Format depends on args.Logger. Default is RFC3999.
-//   - "level": Normally INFO, may be ERROR on errs other than io.ErrClosedPipe.
+//   - "level": Decided by args.Levels, see Levels.
 //   - "msg": Set to args.Msg. Will be "<unset>" if not set.
 //   - "err": Set to write errs.
 //   - "val": Set to values put into this writer, formatted by args.Fmt.
@@ -177,44 +144,33 @@ func NewStreamedWriter[T any](args NewStreamedWriterArgs[T]) core.Writer[T] {
 	if args.Writer == nil {
 		return core.WriterImpl[T]{}
 	}
-	if args.Logger == nil {
-		args.Logger = defaultLogger
-	}
-	if args.Msg == "" {
-		args.Msg = "<unset>"
-	}
 	if args.Fmt == nil {
 		args.Fmt = func(v T) any { return v }
 	}
 
+	e := newEmitter(args.Logger, args.Msg, args.CtxKeys, args.Levels, args.Sampling, args.LogEnd)
 	return core.WriterImpl[T]{
 		Impl: func(ctx context.Context, val T) (err error) {
 			err = args.Writer.Write(ctx, val)
 			if err == io.ErrClosedPipe {
+				e.end(ctx, err)
 				return
 			}
 
-			l := args.Logger
-			l = l.With("err", err)
-			l = l.With("val", args.Fmt(val))
-			l = l.With("ctx", ctxValues(ctx, args.CtxKeys))
-
-			f := l.Info
-			if err != nil {
-				f = l.Error
-			}
-
-			f(args.Msg)
+			e.emit(ctx, err, 1, func() slog.Attr { return slog.Any("val", args.Fmt(val)) })
 			return err
 		},
 	}
 }
 
 type NewBatchedWriterArgs[T any] struct {
-	Writer  core.Writer[[]T] // On nil, the func returns core.WriterImpl[[]T]
-	Logger  *slog.Logger     // On nil, will use a default logger.
-	Msg     string           // On "" , will set the log "msg" to "<unset>"
-	CtxKeys []string         // On nil, will set the log "ctx" to nil.
+	Writer   core.Writer[[]T] // On nil, the func returns core.WriterImpl[[]T]
+	Logger   *slog.Logger     // On nil, will use a default logger.
+	Msg      string           // On "" , will set the log "msg" to "<unset>"
+	CtxKeys  []string         // On nil, the log "ctx" is omitted.
+	Sampling *Sampling        // On nil, all records are logged.
+	Levels   *Levels          // On nil, will use DefaultLevels.
+	LogEnd   bool             // If true, io.ErrClosedPipe is logged once with "count".
 }
 
 // NewBatchedWriter returns a writer which accepts batches and passes them to
@@ -226,7 +182,7 @@ type NewBatchedWriterArgs[T any] struct {
 //
 // Logging format details:
 //   - "time": Format depends on args.Logger. Default is RFC3999.
-//   - "level": Normally INFO, may be ERROR on errs other than io.ErrClosedPipe.
+//   - "level": Decided by args.Levels, see Levels.
 //   - "msg": Set to args.Msg. Will be "<unset>" if not set.
 //   - "err": Set to write errs.
 //   - "len": Set to the len of values put into this writer.
@@ -238,31 +194,17 @@ func NewBatchedWriter[T any](args NewBatchedWriterArgs[T]) core.Writer[[]T] {
 	if args.Writer == nil {
 		return core.WriterImpl[[]T]{}
 	}
-	if args.Logger == nil {
-		args.Logger = defaultLogger
-	}
-	if args.Msg == "" {
-		args.Msg = "<unset>"
-	}
 
+	e := newEmitter(args.Logger, args.Msg, args.CtxKeys, args.Levels, args.Sampling, args.LogEnd)
 	return core.WriterImpl[[]T]{
 		Impl: func(ctx context.Context, s []T) (err error) {
 			err = args.Writer.Write(ctx, s)
 			if err == io.ErrClosedPipe {
+				e.end(ctx, err)
 				return
 			}
 
-			l := args.Logger
-			l = l.With("err", err)
-			l = l.With("len", len(s))
-			l = l.With("ctx", ctxValues(ctx, args.CtxKeys))
-
-			f := l.Info
-			if err != nil {
-				f = l.Error
-			}
-
-			f(args.Msg)
+			e.emit(ctx, err, len(s), func() slog.Attr { return slog.Int("len", len(s)) })
 			return err
 		},
 	}
@@ -0,0 +1,123 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampling configures how many records are logged by the loggers in this pkg,
+// which is useful for high volume streams. It works much like the sampler of
+// zap: the First records of each Interval are logged, then every Thereafter
+// record. On top of that, MaxRate caps the number of records per second.
+//
+// Suppressed records are counted, and the count is logged (with a "suppressed"
+// attribute) on the first log call after the Interval where they happened, or
+// at the end of the stream (io.EOF for readers, io.ErrClosedPipe for writers).
+// Counts of streams which go quiet without ending are logged on the next call.
+type Sampling struct {
+	// First is the number of records which are logged in each Interval
+	// before sampling starts. If both First and Thereafter are <= 0, then
+	// records are not sampled by count, i.e only MaxRate applies.
+	First int
+	// Thereafter makes every Thereafter record after First be logged, for
+	// each Interval. On <= 0, no records are logged after First.
+	Thereafter int
+	// Interval is the sampling period. On <= 0, defaults to 1s.
+	Interval time.Duration
+	// MaxRate is the max number of records logged per second, it may be
+	// fractional (e.g 0.1 for one record per 10s). Up to max(MaxRate, 1)
+	// records may be logged in a burst. On <= 0, there is no max rate.
+	MaxRate float64
+	// AlwaysErrors makes records with errs bypass sampling and MaxRate.
+	AlwaysErrors bool
+}
+
+// sampler implements Sampling. A nil *sampler allows everything.
+type sampler struct {
+	mx     sync.Mutex
+	cfg    Sampling
+	now    func() time.Time
+	start  time.Time // Start of current interval.
+	n      int       // Records in current interval.
+	supp   int       // Suppressed in current interval.
+	tokens float64   // For MaxRate.
+	last   time.Time // Last token refill.
+}
+
+func newSampler(cfg *Sampling) *sampler {
+	if cfg == nil {
+		return nil
+	}
+
+	s := &sampler{cfg: *cfg, now: time.Now}
+	if s.cfg.Interval <= 0 {
+		s.cfg.Interval = time.Second
+	}
+
+	s.start = s.now()
+	s.last = s.start
+	s.tokens = max(s.cfg.MaxRate, 1)
+	return s
+}
+
+// allow reports whether a record should be logged. It also returns the number
+// of records which were suppressed in the previous interval, this is only
+// non-zero once per interval.
+func (s *sampler) allow(isErr bool) (ok bool, suppressed int) {
+	if s == nil {
+		return true, 0
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	now := s.now()
+	if now.Sub(s.start) >= s.cfg.Interval {
+		suppressed = s.supp
+		s.start = now
+		s.n = 0
+		s.supp = 0
+	}
+
+	if isErr && s.cfg.AlwaysErrors {
+		return true, suppressed
+	}
+
+	s.n++
+	ok = s.cfg.First <= 0 && s.cfg.Thereafter <= 0
+	ok = ok || s.n <= s.cfg.First
+	if !ok && s.cfg.Thereafter > 0 {
+		ok = (s.n-s.cfg.First)%s.cfg.Thereafter == 0
+	}
+
+	if ok && s.cfg.MaxRate > 0 {
+		s.tokens += now.Sub(s.last).Seconds() * s.cfg.MaxRate
+		s.tokens = min(s.tokens, max(s.cfg.MaxRate, 1))
+		s.last = now
+
+		ok = s.tokens >= 1
+		if ok {
+			s.tokens--
+		}
+	}
+
+	if !ok {
+		s.supp++
+	}
+
+	return ok, suppressed
+}
+
+// flush returns the number of records suppressed in the current interval and
+// resets it, it is used at the end of a stream.
+func (s *sampler) flush() (suppressed int) {
+	if s == nil {
+		return 0
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	suppressed, s.supp = s.supp, 0
+	return suppressed
+}
@@ -0,0 +1,180 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func tfNewBufLogger() (*slog.Logger, *bytes.Buffer) {
+	b := bytes.NewBuffer(nil)
+	return slog.New(slog.NewJSONHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug})), b
+}
+
+func tfRecords(b *bytes.Buffer) []map[string]any {
+	recs := make([]map[string]any, 0, 8)
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		m := make(map[string]any)
+		json.Unmarshal([]byte(line), &m)
+		recs = append(recs, m)
+	}
+
+	return recs
+}
+
+func TestSamplerFirstThereafter(t *testing.T) {
+	s := newSampler(&Sampling{First: 2, Thereafter: 3, Interval: time.Hour})
+
+	logged := make([]bool, 0, 8)
+	for i := 0; i < 8; i++ {
+		ok, _ := s.allow(false)
+		logged = append(logged, ok)
+	}
+
+	want := []bool{true, true, false, false, true, false, false, true}
+	for i := range want {
+		if want[i] != logged[i] {
+			t.Fatalf("unexpected sampling: %v", logged)
+		}
+	}
+}
+
+func TestSamplerSuppressedAndInterval(t *testing.T) {
+	now := time.Now()
+	s := newSampler(&Sampling{First: 1, Interval: time.Second})
+	s.now = func() time.Time { return now }
+	s.start, s.last = now, now
+
+	s.allow(false)
+	s.allow(false)
+	s.allow(false)
+
+	now = now.Add(time.Second)
+	ok, suppressed := s.allow(false)
+	if !ok || suppressed != 2 {
+		t.Fatalf("unexpected ok/suppressed: %v/%v", ok, suppressed)
+	}
+
+	_, suppressed = s.allow(false)
+	if suppressed != 0 {
+		t.Fatalf("unexpected suppressed: %v", suppressed)
+	}
+}
+
+func TestSamplerMaxRateAndAlwaysErrors(t *testing.T) {
+	now := time.Now()
+	s := newSampler(&Sampling{First: 100, MaxRate: 2, AlwaysErrors: true, Interval: time.Hour})
+	s.now = func() time.Time { return now }
+	s.start, s.last = now, now
+
+	n := 0
+	for i := 0; i < 5; i++ {
+		if ok, _ := s.allow(false); ok {
+			n++
+		}
+	}
+
+	if n != 2 {
+		t.Fatalf("unexpected logged count: %v", n)
+	}
+
+	if ok, _ := s.allow(true); !ok {
+		t.Fatal("expected err to be logged")
+	}
+
+	now = now.Add(time.Second / 2)
+	if ok, _ := s.allow(false); !ok {
+		t.Fatal("expected a refilled token")
+	}
+}
+
+func TestNewStreamedReaderWithSampling(t *testing.T) {
+	l, b := tfNewBufLogger()
+
+	tfReadAll(
+		tvCtx,
+		NewStreamedReader(
+			NewStreamedReaderArgs[int]{
+				Reader:   core.NewReaderFrom(1, 2, 3, 4, 5),
+				Logger:   l,
+				Sampling: &Sampling{First: 2, Interval: time.Hour},
+			},
+		),
+	)
+
+	// 2 values, then the suppressed count at io.EOF.
+	recs := tfRecords(b)
+	if n := len(recs); n != 3 {
+		t.Fatalf("unexpected record count: %v", n)
+	}
+
+	tfAssertEq(t, "suppressed", float64(3), recs[2]["suppressed"])
+}
+
+func TestNewBatchedWriterWithSamplingAndErrs(t *testing.T) {
+	l, b := tfNewBufLogger()
+
+	w := NewBatchedWriter(
+		NewBatchedWriterArgs[int]{
+			Writer: core.WriterImpl[[]int]{
+				Impl: func(ctx context.Context, s []int) error { return tvErr },
+			},
+			Logger:   l,
+			Sampling: &Sampling{AlwaysErrors: true},
+		},
+	)
+
+	w.Write(tvCtx, []int{1})
+	w.Write(tvCtx, []int{2})
+
+	if n := len(tfRecords(b)); n != 2 {
+		t.Fatalf("unexpected record count: %v", n)
+	}
+}
+
+func TestSamplerWithMaxRateOnly(t *testing.T) {
+	now := time.Now()
+	s := newSampler(&Sampling{MaxRate: 100})
+	s.now = func() time.Time { return now }
+	s.start, s.last = now, now
+
+	n := 0
+	for i := 0; i < 10; i++ {
+		if ok, _ := s.allow(false); ok {
+			n++
+		}
+	}
+
+	if n != 10 {
+		t.Fatalf("unexpected logged count: %v", n)
+	}
+}
+
+func TestSamplerWithFractionalMaxRate(t *testing.T) {
+	now := time.Now()
+	s := newSampler(&Sampling{MaxRate: 0.5})
+	s.now = func() time.Time { return now }
+	s.start, s.last = now, now
+
+	if ok, _ := s.allow(false); !ok {
+		t.Fatal("expected the first record to be logged")
+	}
+	if ok, _ := s.allow(false); ok {
+		t.Fatal("expected the second record to be suppressed")
+	}
+
+	now = now.Add(time.Second * 2)
+	if ok, _ := s.allow(false); !ok {
+		t.Fatal("expected a refilled token")
+	}
+}
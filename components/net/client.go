@@ -0,0 +1,218 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	stdnet "net"
+	"sync"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// errBadAck is given when the remote responds with something else than an ack
+// of the last frame, which makes the client reconnect.
+var errBadAck = errors.New("net: unexpected ack")
+
+type NewWriterArgs[T any] struct {
+	// Network is e.g "tcp" or "unix". On "", defaults to "tcp".
+	Network string
+	// Address is dialed. On "", the func returns core.WriteCloserImpl[T].
+	Address string
+	// Dialer dials the remote. On nil, defaults to a *net.Dialer with a
+	// timeout of 10s.
+	Dialer interface {
+		DialContext(ctx context.Context, network, address string) (stdnet.Conn, error)
+	}
+	// Encoder encodes values. On nil, defaults to json.NewEncoder.
+	Encoder func(io.Writer) core.Encoder
+	// Retries is the max number of times a frame is resent (reconnecting
+	// first) after a failure. On <= 0, defaults to 3.
+	Retries int
+	// Backoff is the delay before the first retry, it is doubled for each
+	// subsequent retry. On <= 0, defaults to 100ms.
+	Backoff time.Duration
+}
+
+// NewWriter returns a WriteCloser which sends values over a TCP or unix socket
+// to a NewReader on the remote. Each write blocks until the remote has
+// acknowledged that the value was delivered by a read there. If the connection
+// fails, then it is re-dialed and the value is resent, up to args.Retries
+// times, which gives at-least-once delivery (a value may be delivered twice
+// if an ack is lost).
+//
+// Close sends a fin, which tells the remote that this writer is done, such
+// that it may give io.EOF, and then closes the connection. A writer which is
+// never closed therefore holds back io.EOF on the remote. Writes after Close
+// give io.ErrClosedPipe. The ctx of a write bounds the whole write, including
+// retries. The returned WriteCloser is safe for concurrent use.
+//
+// Example:
+//
+//	w := NewWriter(NewWriterArgs[event]{Network: "unix", Address: "/tmp/events.sock"})
+//	defer w.Close()
+func NewWriter[T any](args NewWriterArgs[T]) core.WriteCloser[T] {
+	if args.Address == "" {
+		return core.WriteCloserImpl[T]{}
+	}
+	if args.Network == "" {
+		args.Network = "tcp"
+	}
+	if args.Dialer == nil {
+		args.Dialer = &stdnet.Dialer{Timeout: time.Second * 10}
+	}
+	if args.Encoder == nil {
+		args.Encoder = func(w io.Writer) core.Encoder { return json.NewEncoder(w) }
+	}
+	if args.Retries <= 0 {
+		args.Retries = 3
+	}
+	if args.Backoff <= 0 {
+		args.Backoff = time.Millisecond * 100
+	}
+
+	c := &client[T]{args: args, id: make([]byte, 8)}
+	rand.Read(c.id)
+
+	return core.WriteCloserImpl[T]{
+		ImplC: c.close,
+		ImplW: c.write,
+	}
+}
+
+type client[T any] struct {
+	mx     sync.Mutex
+	args   NewWriterArgs[T]
+	id     []byte // Random writer ID, sent in a hello on each connection.
+	conn   stdnet.Conn
+	seq    uint64
+	closed bool
+}
+
+func (c *client[T]) write(ctx context.Context, v T) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.closed {
+		return io.ErrClosedPipe
+	}
+
+	b := bytes.NewBuffer(nil)
+	if err := c.args.Encoder(b).Encode(v); err != nil {
+		return err
+	}
+
+	c.seq++
+	return c.send(ctx, frameData, b.Bytes())
+}
+
+func (c *client[T]) close() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+	c.seq++
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	err := c.send(ctx, frameFin, nil)
+	if c.conn != nil {
+		err = errors.Join(err, c.conn.Close())
+		c.conn = nil
+	}
+
+	return err
+}
+
+// send sends a frame with the current seq and waits for the ack, with retries.
+// The mutex must be held.
+func (c *client[T]) send(ctx context.Context, t frameType, payload []byte) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	backoff := c.args.Backoff
+	for attempt := 0; ; attempt++ {
+		if err = c.sendOnce(ctx, t, payload); err == nil {
+			return nil
+		}
+
+		// The conn is in an unknown state.
+		if c.conn != nil {
+			c.conn.Close()
+			c.conn = nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= c.args.Retries {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+	}
+}
+
+func (c *client[T]) sendOnce(ctx context.Context, t frameType, payload []byte) error {
+	hello := c.conn == nil
+	if hello {
+		conn, err := c.args.Dialer.DialContext(ctx, c.args.Network, c.args.Address)
+		if err != nil {
+			return err
+		}
+
+		c.conn = conn
+	}
+
+	// Interrupt blocking I/O when ctx is done. The deadline is set first, such
+	// that it can not overwrite the one set by the AfterFunc.
+	conn := c.conn
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if hello {
+		if err := c.exchange(conn, frameHello, c.id); err != nil {
+			return err
+		}
+	}
+
+	return c.exchange(conn, t, payload)
+}
+
+// exchange writes a frame with the current seq and reads its ack.
+func (c *client[T]) exchange(conn stdnet.Conn, t frameType, payload []byte) error {
+	if err := writeFrame(conn, t, c.seq, payload); err != nil {
+		return err
+	}
+
+	at, seq, _, err := readFrame(conn)
+	if err != nil {
+		return err
+	}
+	if at != frameAck || seq != c.seq {
+		return errBadAck
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package net
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Frames are the unit of the wire protocol used by NewWriter and NewReader:
+//
+//	[len uint32][type byte][seq uint64][payload]
+//
+// All ints are big endian, 'len' is the size of everything after itself.
+// The writing side sends a hello frame (with its writer ID as payload) first
+// on each connection, then data frames (with an encoded value as payload) and
+// a fin frame on Close, the reading side responds with an ack frame with the
+// same seq for each of them.
+
+type frameType byte
+
+const (
+	frameData frameType = iota + 1
+	frameAck
+	frameFin
+	frameHello
+)
+
+// frameHeaderLen is the size of type + seq.
+const frameHeaderLen = 1 + 8
+
+// MaxFrameSize is the max size of a frame, larger frames are rejected with
+// ErrFrameTooLarge.
+const MaxFrameSize = 64 << 20
+
+// ErrFrameTooLarge is given when a frame is larger than MaxFrameSize, or when
+// a frame is malformed.
+var ErrFrameTooLarge = errors.New("net: frame too large or malformed")
+
+func writeFrame(w io.Writer, t frameType, seq uint64, payload []byte) error {
+	n := frameHeaderLen + len(payload)
+	if n > MaxFrameSize {
+		return ErrFrameTooLarge
+	}
+
+	b := make([]byte, 4+n)
+	binary.BigEndian.PutUint32(b[0:4], uint32(n))
+	b[4] = byte(t)
+	binary.BigEndian.PutUint64(b[5:13], seq)
+	copy(b[13:], payload)
+
+	_, err := w.Write(b)
+	return err
+}
+
+func readFrame(r io.Reader) (t frameType, seq uint64, payload []byte, err error) {
+	var head [4 + frameHeaderLen]byte
+	if _, err = io.ReadFull(r, head[:]); err != nil {
+		return
+	}
+
+	n := binary.BigEndian.Uint32(head[0:4])
+	if n < frameHeaderLen || n > MaxFrameSize {
+		return 0, 0, nil, ErrFrameTooLarge
+	}
+
+	t = frameType(head[4])
+	seq = binary.BigEndian.Uint64(head[5:13])
+	payload = make([]byte, n-frameHeaderLen)
+	if _, err = io.ReadFull(r, payload); err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return
+}
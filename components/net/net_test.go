@@ -0,0 +1,268 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	stdnet "net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func assertEq[T any](subject string, want T, have T, f func(string)) {
+	if f == nil {
+		return
+	}
+
+	ab, _ := json.Marshal(want)
+	bb, _ := json.Marshal(have)
+
+	as := string(ab)
+	bs := string(bb)
+
+	if as == bs {
+		return
+	}
+
+	s := "unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n"
+	f(fmt.Sprintf(s, subject, as, bs))
+}
+
+func tfReadAll[T any](ctx context.Context, r core.Reader[T]) ([]T, error) {
+	var v T
+	var s = make([]T, 0, 8)
+	var err error
+
+	for v, err = r.Read(ctx); err == nil; v, err = r.Read(ctx) {
+		s = append(s, v)
+	}
+
+	return s, err
+}
+
+func tfListen(t *testing.T, network string) stdnet.Listener {
+	address := "127.0.0.1:0"
+	if network == "unix" {
+		address = filepath.Join(t.TempDir(), "test.sock")
+	}
+
+	l, err := stdnet.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return l
+}
+
+// tfWriteAll writes 'vals' to 'w' and closes it, in a goroutine. The returned
+// chan gives the first err.
+func tfWriteAll[T any](w core.WriteCloser[T], vals ...T) chan error {
+	ch := make(chan error, 1)
+	go func() {
+		for _, v := range vals {
+			if err := w.Write(context.Background(), v); err != nil {
+				ch <- err
+				return
+			}
+		}
+
+		ch <- w.Close()
+	}()
+
+	return ch
+}
+
+// tfFlakyDialer dials conns where the first read after the hello ack of the
+// first conn fails.
+type tfFlakyDialer struct {
+	dials atomic.Int64
+}
+
+func (d *tfFlakyDialer) DialContext(ctx context.Context, network, address string) (stdnet.Conn, error) {
+	conn, err := (&stdnet.Dialer{}).DialContext(ctx, network, address)
+	if err != nil || d.dials.Add(1) > 1 {
+		return conn, err
+	}
+
+	return &tfFlakyConn{Conn: conn}, nil
+}
+
+type tfFlakyConn struct {
+	stdnet.Conn
+	n int // Bytes read.
+}
+
+func (c *tfFlakyConn) Read(b []byte) (int, error) {
+	// Pass the hello ack through.
+	if c.n < 4+frameHeaderLen {
+		n, err := c.Conn.Read(b[:min(len(b), 4+frameHeaderLen-c.n)])
+		c.n += n
+		return n, err
+	}
+
+	// Wait for the ack, then lose it.
+	c.Conn.Read(b)
+	return 0, io.ErrUnexpectedEOF
+}
+
+// -----------------------------------------------------------------------------
+// Tests: Frames.
+// -----------------------------------------------------------------------------
+
+func TestFrameRoundtrip(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	writeFrame(b, frameData, 7, []byte("test"))
+
+	typ, seq, payload, err := readFrame(b)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("type", frameData, typ, func(s string) { t.Fatal(s) })
+	assertEq("seq", uint64(7), seq, func(s string) { t.Fatal(s) })
+	assertEq("payload", "test", string(payload), func(s string) { t.Fatal(s) })
+
+	_, _, _, err = readFrame(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 1, 0, 0, 0, 0, 0, 0, 0, 0}))
+	assertEq("err", ErrFrameTooLarge, err, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewWriter and NewReader.
+// -----------------------------------------------------------------------------
+
+func TestNewWriterIdeal(t *testing.T) {
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run(network, func(t *testing.T) {
+			l := tfListen(t, network)
+			r := NewReader(NewReaderArgs[int]{Listener: l})
+			defer r.Close()
+
+			w := NewWriter(NewWriterArgs[int]{Network: network, Address: l.Addr().String()})
+			errs := tfWriteAll(w, 1, 2, 3)
+
+			vals, err := tfReadAll(context.Background(), r)
+			assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+			assertEq("vals", []int{1, 2, 3}, vals, func(s string) { t.Fatal(s) })
+			assertEq("err", *new(error), <-errs, func(s string) { t.Fatal(s) })
+
+			err = w.Write(context.Background(), 4)
+			assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+		})
+	}
+}
+
+func TestNewWriterWithLostAck(t *testing.T) {
+	l := tfListen(t, "tcp")
+	r := NewReader(NewReaderArgs[int]{Listener: l})
+	defer r.Close()
+
+	d := &tfFlakyDialer{}
+	w := NewWriter(NewWriterArgs[int]{Address: l.Addr().String(), Dialer: d, Backoff: time.Millisecond})
+	errs := tfWriteAll(w, 1, 2)
+
+	// At-least-once: 1 is resent since its ack was lost.
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 1, 2}, vals, func(s string) { t.Fatal(s) })
+	assertEq("err", *new(error), <-errs, func(s string) { t.Fatal(s) })
+	assertEq("dials", int64(2), d.dials.Load(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithSeveralWriters(t *testing.T) {
+	l := tfListen(t, "tcp")
+	r := NewReader(NewReaderArgs[int]{Listener: l})
+	defer r.Close()
+
+	w1 := NewWriter(NewWriterArgs[int]{Address: l.Addr().String()})
+	w2 := NewWriter(NewWriterArgs[int]{Address: l.Addr().String()})
+
+	go w1.Write(context.Background(), 1)
+	v, _ := r.Read(context.Background())
+	assertEq("val", 1, v, func(s string) { t.Fatal(s) })
+
+	go w2.Write(context.Background(), 2)
+	v, _ = r.Read(context.Background())
+	assertEq("val", 2, v, func(s string) { t.Fatal(s) })
+
+	assertEq("err", *new(error), w1.Close(), func(s string) { t.Fatal(s) })
+
+	// w1 sent a fin, but w2 is still connected.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	_, err := r.Read(ctx)
+	assertEq("err", context.DeadlineExceeded, err, func(s string) { t.Fatal(s) })
+
+	assertEq("err", *new(error), w2.Close(), func(s string) { t.Fatal(s) })
+	_, err = r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithReconnectingWriter(t *testing.T) {
+	l := tfListen(t, "tcp")
+	r := NewReader(NewReaderArgs[int]{Listener: l})
+	defer r.Close()
+
+	w1 := NewWriter(NewWriterArgs[int]{Address: l.Addr().String()})
+	w2 := NewWriter(NewWriterArgs[int]{Address: l.Addr().String(), Dialer: &tfFlakyDialer{}, Backoff: time.Millisecond * 100})
+
+	// The ack is lost, so w2 drops its conn and waits before it reconnects.
+	errs := tfWriteAll(w2, 1)
+	v, _ := r.Read(context.Background())
+	assertEq("val", 1, v, func(s string) { t.Fatal(s) })
+
+	go w1.Write(context.Background(), 2)
+	v, _ = r.Read(context.Background())
+	assertEq("val", 2, v, func(s string) { t.Fatal(s) })
+	assertEq("err", *new(error), w1.Close(), func(s string) { t.Fatal(s) })
+
+	// w1 sent a fin and no conns are left, but w2 has not sent a fin.
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1}, vals, func(s string) { t.Fatal(s) })
+	assertEq("err", *new(error), <-errs, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithoutHello(t *testing.T) {
+	l := tfListen(t, "tcp")
+	r := NewReader(NewReaderArgs[int]{Listener: l})
+	defer r.Close()
+
+	conn, err := stdnet.Dial("tcp", l.Addr().String())
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	defer conn.Close()
+
+	// The conn is closed rather than acked.
+	writeFrame(conn, frameData, 1, []byte("1"))
+	if _, _, _, err = readFrame(conn); err != io.EOF {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+func TestNewReaderWithClose(t *testing.T) {
+	l := tfListen(t, "tcp")
+	r := NewReader(NewReaderArgs[int]{Listener: l})
+
+	assertEq("err", *new(error), r.Close(), func(s string) { t.Fatal(s) })
+
+	_, err := r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+
+	w := NewWriter(NewWriterArgs[int]{Address: l.Addr().String(), Retries: 1, Backoff: time.Millisecond})
+	if err := w.Write(context.Background(), 1); err == nil {
+		t.Fatal("expected an err")
+	}
+}
+
+func TestNewReaderWithNilListener(t *testing.T) {
+	_, err := NewReader(NewReaderArgs[int]{}).Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithEmptyAddress(t *testing.T) {
+	err := NewWriter(NewWriterArgs[int]{}).Write(context.Background(), 1)
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
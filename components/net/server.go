@@ -0,0 +1,241 @@
+package net
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	stdnet "net"
+	"sync"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+type NewReaderArgs[T any] struct {
+	// Listener accepts connections from NewWriter, e.g from net.Listen. It is
+	// closed by the returned ReadCloser. On nil, the func returns
+	// core.ReadCloserImpl[T].
+	Listener stdnet.Listener
+	// Decoder decodes values. On nil, defaults to json.NewDecoder.
+	Decoder func(io.Reader) core.Decoder
+}
+
+// delivery is a value on its way from a connection to Read.
+type delivery[T any] struct {
+	val  T
+	done chan struct{} // Closed by Read, after which the value is acked.
+}
+
+// NewReader returns a ReadCloser which accepts connections from NewWriter (on
+// other processes) with args.Listener, and gives the values they send. Each
+// value is acknowledged to the sender after it is returned from Read, which
+// gives at-least-once delivery together with NewWriter. Any number of writers
+// may be connected at the same time.
+//
+// Read gives io.EOF after a writer has closed (sent a fin), when every other
+// writer which has connected has closed as well and all values are read, after
+// which no more connections are accepted. Writers are told apart by a random
+// ID which they send on each connection, so a writer whose connection failed
+// without a fin is assumed to be reconnecting and holds back io.EOF, until it
+// closes or until Close is called here. Writers which have not connected yet
+// are not known, so they may miss io.EOF. Frames which can not be decoded make
+// the connection close.
+//
+// Close closes args.Listener and all connections, after which Read gives
+// io.EOF. If the ctx given to Read is done while waiting, then ctx.Err() is
+// returned. The returned ReadCloser is safe for concurrent use.
+//
+// Example:
+//
+//	l, _ := stdnet.Listen("unix", "/tmp/events.sock")
+//	r := NewReader(NewReaderArgs[event]{Listener: l})
+//	defer r.Close()
+func NewReader[T any](args NewReaderArgs[T]) core.ReadCloser[T] {
+	if args.Listener == nil {
+		return core.ReadCloserImpl[T]{}
+	}
+	if args.Decoder == nil {
+		args.Decoder = func(r io.Reader) core.Decoder { return json.NewDecoder(r) }
+	}
+
+	s := &server[T]{
+		args:    args,
+		ch:      make(chan delivery[T]),
+		eof:     make(chan struct{}),
+		closed:  make(chan struct{}),
+		conns:   make(map[stdnet.Conn]struct{}),
+		writers: make(map[string]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.accept()
+
+	return core.ReadCloserImpl[T]{
+		ImplC: s.close,
+		ImplR: s.read,
+	}
+}
+
+type server[T any] struct {
+	args NewReaderArgs[T]
+	ch   chan delivery[T]
+	wg   sync.WaitGroup
+
+	mx      sync.Mutex
+	conns   map[stdnet.Conn]struct{}
+	writers map[string]struct{} // IDs of writers which have not sent a fin.
+	finSeen bool
+	eofOnce sync.Once
+	eof     chan struct{} // Closed when fin is seen and no conns or writers are active.
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (s *server[T]) read(ctx context.Context) (val T, err error) {
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+
+	select {
+	case d := <-s.ch:
+		close(d.done)
+		return d.val, nil
+	case <-s.eof:
+		return val, io.EOF
+	case <-s.closed:
+		return val, io.EOF
+	case <-done:
+		return val, ctx.Err()
+	}
+}
+
+func (s *server[T]) close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		err = s.args.Listener.Close()
+
+		s.mx.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mx.Unlock()
+
+		s.wg.Wait()
+	})
+
+	if errors.Is(err, stdnet.ErrClosed) {
+		err = nil // Closed on io.EOF.
+	}
+
+	return err
+}
+
+func (s *server[T]) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.args.Listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mx.Lock()
+		select {
+		case <-s.eof:
+			s.mx.Unlock()
+			conn.Close()
+			return
+		default:
+		}
+
+		s.conns[conn] = struct{}{}
+		s.mx.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+// handle reads frames from 'conn' until it fails or sends a fin. The first
+// frame must be a hello with the ID of the writer.
+func (s *server[T]) handle(conn stdnet.Conn) {
+	defer s.wg.Done()
+
+	id := ""
+	fin := false
+	defer func() {
+		conn.Close()
+
+		s.mx.Lock()
+		defer s.mx.Unlock()
+
+		delete(s.conns, conn)
+		if fin {
+			delete(s.writers, id)
+		}
+
+		s.finSeen = s.finSeen || fin
+		if s.finSeen && len(s.conns) == 0 && len(s.writers) == 0 {
+			s.eofOnce.Do(func() {
+				close(s.eof)
+				s.args.Listener.Close()
+			})
+		}
+	}()
+
+	for {
+		t, seq, payload, err := readFrame(conn)
+		if err != nil {
+			return
+		}
+
+		if (id == "") != (t == frameHello) {
+			return
+		}
+
+		switch t {
+		case frameHello:
+			if len(payload) == 0 {
+				return
+			}
+
+			id = string(payload)
+			s.mx.Lock()
+			s.writers[id] = struct{}{}
+			s.mx.Unlock()
+
+			if err := writeFrame(conn, frameAck, seq, nil); err != nil {
+				return
+			}
+
+		case frameFin:
+			fin = true
+			writeFrame(conn, frameAck, seq, nil)
+			return
+
+		case frameData:
+			d := delivery[T]{done: make(chan struct{})}
+			if err := s.args.Decoder(bytes.NewReader(payload)).Decode(&d.val); err != nil {
+				return
+			}
+
+			select {
+			case s.ch <- d:
+			case <-s.closed:
+				return
+			}
+
+			<-d.done
+			if err := writeFrame(conn, frameAck, seq, nil); err != nil {
+				return
+			}
+
+		default:
+			return
+		}
+	}
+}
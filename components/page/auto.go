@@ -0,0 +1,135 @@
+package page
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+type NewAutoReaderArgs[T any] struct {
+	// Fetch fetches the values of a page. On nil, the func returns
+	// core.ReaderImpl[Paged[[]T]].
+	Fetch func(ctx context.Context, p Page) ([]T, error)
+	// Count is optional, it is called once before the first fetch, and the
+	// result is used as Page.Total, such that reading stops at Total. On nil,
+	// Page.Total is 0 and reading only stops on a short or empty page.
+	Count func(ctx context.Context) (int, error)
+
+	// Limit is the initial page limit. On <= 0, defaults to 100.
+	Limit int
+	// MinLimit bounds the adapted limit. On <= 0, defaults to 1.
+	MinLimit int
+	// MaxLimit bounds the adapted limit. On <= 0, defaults to 10 * Limit.
+	MaxLimit int
+	// TargetLatency makes the limit adapt such that fetches take about this
+	// long, i.e the limit is scaled by TargetLatency / latency of the last
+	// fetch, by a factor of at most 2 in either direction. On <= 0, the limit
+	// is not adapted by latency.
+	TargetLatency time.Duration
+	// Adapt is optional, it is given the limit and latency of the last fetch
+	// along with the number of values it gave, and returns the next limit. It
+	// takes precedence over TargetLatency, and may be used for e.g adapting
+	// by response size. The result is bounded by MinLimit and MaxLimit.
+	Adapt func(limit, n int, latency time.Duration) int
+}
+
+// NewAutoReader returns a Reader which fetches pages with args.Fetch, without
+// needing a known total: paging stops on the first page which has fewer values
+// than its limit (or none), after which io.EOF is returned. If args.Count is
+// set, then paging also stops when the total is reached. The limit of pages
+// may adapt to fetch latency or other signals, see NewAutoReaderArgs.
+//
+// Errs from args.Count and args.Fetch are returned as-is, and the same call
+// is tried again on the next read.
+//
+// Example:
+//
+//	r := NewAutoReader(
+//	    NewAutoReaderArgs[user]{
+//	        Fetch: func(ctx context.Context, p Page) ([]user, error) {
+//	            return myClient.ListUsers(ctx, p.Skip, p.Limit)
+//	        },
+//	        Limit:         100,
+//	        MaxLimit:      5000,
+//	        TargetLatency: time.Millisecond * 200,
+//	    },
+//	)
+func NewAutoReader[T any](args NewAutoReaderArgs[T]) core.Reader[Paged[[]T]] {
+	if args.Fetch == nil {
+		return core.ReaderImpl[Paged[[]T]]{}
+	}
+	if args.Limit <= 0 {
+		args.Limit = 100
+	}
+	if args.MinLimit <= 0 {
+		args.MinLimit = 1
+	}
+	if args.MaxLimit <= 0 {
+		args.MaxLimit = args.Limit * 10
+	}
+	if args.MaxLimit < args.MinLimit {
+		args.MaxLimit = args.MinLimit
+	}
+	if args.Adapt == nil && args.TargetLatency > 0 {
+		args.Adapt = func(limit, n int, latency time.Duration) int {
+			f := float64(args.TargetLatency) / float64(max(latency, 1))
+			f = max(0.5, min(2, f))
+			return int(float64(limit) * f)
+		}
+	}
+
+	skip := 0
+	limit := max(args.MinLimit, min(args.MaxLimit, args.Limit))
+	total := 0
+	counted := args.Count == nil
+	done := false
+
+	return core.ReaderImpl[Paged[[]T]]{
+		Impl: func(ctx context.Context) (val Paged[[]T], err error) {
+			if done {
+				return val, io.EOF
+			}
+			if !counted {
+				total, err = args.Count(ctx)
+				if err != nil {
+					return val, err
+				}
+
+				counted = true
+			}
+
+			p := Page{Skip: skip, Limit: limit, Total: total}
+			if args.Count != nil {
+				if skip >= total {
+					done = true
+					return val, io.EOF
+				}
+
+				p.Limit = min(limit, total-skip)
+			}
+
+			ts := time.Now()
+			vals, err := args.Fetch(ctx, p)
+			if err != nil {
+				return val, err
+			}
+
+			latency := time.Since(ts)
+
+			skip += len(vals)
+			done = len(vals) < p.Limit
+			if len(vals) == 0 {
+				return val, io.EOF
+			}
+
+			if args.Adapt != nil {
+				limit = args.Adapt(p.Limit, len(vals), latency)
+				limit = max(args.MinLimit, min(args.MaxLimit, limit))
+			}
+
+			return Paged[[]T]{Page: p, Val: vals}, nil
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package page
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// tfFetcher fetches from [0, n), recording the limits it was given.
+func tfFetcher(n int, limits *[]int) func(context.Context, Page) ([]int, error) {
+	return func(ctx context.Context, p Page) ([]int, error) {
+		*limits = append(*limits, p.Limit)
+
+		vals := make([]int, 0, p.Limit)
+		for i := p.Skip; i < n && len(vals) < p.Limit; i++ {
+			vals = append(vals, i)
+		}
+
+		return vals, nil
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewAutoReader.
+// -----------------------------------------------------------------------------
+
+func TestNewAutoReaderIdeal(t *testing.T) {
+	limits := []int{}
+	r := NewAutoReader(NewAutoReaderArgs[int]{Fetch: tfFetcher(7, &limits), Limit: 3})
+
+	pages, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("len", 3, len(pages), func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{6}, pages[2].Val, func(s string) { t.Fatal(s) })
+	assertEq("skip", 6, pages[2].Skip, func(s string) { t.Fatal(s) })
+	assertEq("limits", []int{3, 3, 3}, limits, func(s string) { t.Fatal(s) })
+}
+
+func TestNewAutoReaderWithEmptyLastPage(t *testing.T) {
+	limits := []int{}
+	r := NewAutoReader(NewAutoReaderArgs[int]{Fetch: tfFetcher(6, &limits), Limit: 3})
+
+	pages, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("len", 2, len(pages), func(s string) { t.Fatal(s) })
+	assertEq("limits", []int{3, 3, 3}, limits, func(s string) { t.Fatal(s) })
+}
+
+func TestNewAutoReaderWithCount(t *testing.T) {
+	limits := []int{}
+	r := NewAutoReader(
+		NewAutoReaderArgs[int]{
+			Fetch: tfFetcher(100, &limits),
+			Count: func(ctx context.Context) (int, error) { return 5, nil },
+			Limit: 2,
+		},
+	)
+
+	pages, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("len", 3, len(pages), func(s string) { t.Fatal(s) })
+	assertEq("total", 5, pages[0].Total, func(s string) { t.Fatal(s) })
+	assertEq("limits", []int{2, 2, 1}, limits, func(s string) { t.Fatal(s) })
+}
+
+func TestNewAutoReaderWithAdapt(t *testing.T) {
+	limits := []int{}
+	r := NewAutoReader(
+		NewAutoReaderArgs[int]{
+			Fetch:    tfFetcher(30, &limits),
+			Limit:    2,
+			MaxLimit: 8,
+			Adapt:    func(limit, n int, latency time.Duration) int { return limit * 2 },
+		},
+	)
+
+	_, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("limits", []int{2, 4, 8, 8, 8, 8}, limits, func(s string) { t.Fatal(s) })
+}
+
+func TestNewAutoReaderWithTargetLatency(t *testing.T) {
+	limits := []int{}
+	fetch := tfFetcher(100, &limits)
+
+	r := NewAutoReader(
+		NewAutoReaderArgs[int]{
+			Fetch: func(ctx context.Context, p Page) ([]int, error) {
+				time.Sleep(time.Millisecond * 20)
+				return fetch(ctx, p)
+			},
+			Limit:         8,
+			MinLimit:      2,
+			TargetLatency: time.Millisecond * 5,
+		},
+	)
+
+	for i := 0; i < 4; i++ {
+		r.Read(context.Background())
+	}
+
+	assertEq("limits", []int{8, 4, 2, 2}, limits, func(s string) { t.Fatal(s) })
+}
+
+func TestNewAutoReaderWithNilFetch(t *testing.T) {
+	_, err := NewAutoReader(NewAutoReaderArgs[int]{}).Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,135 @@
+package page
+
+import (
+	"context"
+	"io"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+type NewCursorReaderArgs[T any, C comparable] struct {
+	// Fetch fetches the page at 'cursor', returning its values and the cursor
+	// of the next page. A zero value next cursor (e.g "" for string tokens)
+	// means that there are no more pages. On nil, the func returns
+	// core.ReaderImpl[[]T].
+	Fetch func(ctx context.Context, cursor C) (vals []T, next C, err error)
+	// Start is the cursor of the first page, usually the zero value.
+	Start C
+}
+
+// NewCursorReader returns a Reader of pages fetched with args.Fetch, using
+// cursor based pagination: each page tells where the next one starts, until
+// the next cursor is the zero value of C, after which io.EOF is returned. This
+// covers e.g APIs with opaque string tokens (C = string), see NewKeysetReader
+// for paging by the last seen key (e.g "WHERE id > ?").
+//
+// Empty pages which have a next cursor are skipped. Errs from args.Fetch are
+// returned as-is, and the same cursor is tried again on the next read. Use
+// core.NewReaderWithUnbatching for reading values instead of pages.
+//
+// Example:
+//
+//	r := NewCursorReader(
+//	    NewCursorReaderArgs[int, string]{
+//	        Fetch: func(ctx context.Context, token string) ([]int, string, error) {
+//	            resp, err := myClient.List(ctx, token)
+//	            return resp.Items, resp.NextPageToken, err
+//	        },
+//	    },
+//	)
+func NewCursorReader[T any, C comparable](args NewCursorReaderArgs[T, C]) core.Reader[[]T] {
+	if args.Fetch == nil {
+		return core.ReaderImpl[[]T]{}
+	}
+
+	var zero C
+	cursor := args.Start
+	done := false
+
+	return core.ReaderImpl[[]T]{
+		Impl: func(ctx context.Context) (vals []T, err error) {
+			var next C
+			for !done {
+				vals, next, err = args.Fetch(ctx, cursor)
+				if err != nil {
+					return nil, err
+				}
+
+				cursor = next
+				done = next == zero
+				if len(vals) > 0 {
+					return vals, nil
+				}
+			}
+
+			return nil, io.EOF
+		},
+	}
+}
+
+type NewKeysetReaderArgs[T any, K comparable] struct {
+	// Fetch fetches up to 'limit' values which come after the key 'after',
+	// in key order. On nil, the func returns core.ReaderImpl[[]T].
+	Fetch func(ctx context.Context, after K, limit int) ([]T, error)
+	// Key returns the key of a value, the key of the last value of a page is
+	// given to Fetch for the next page. On nil, the func returns
+	// core.ReaderImpl[[]T].
+	Key func(T) K
+	// Start is given to Fetch for the first page, usually the zero value.
+	Start K
+	// Limit is given to Fetch. On <= 0, defaults to 100.
+	Limit int
+}
+
+// NewKeysetReader returns a Reader of pages fetched with args.Fetch, using
+// keyset pagination: each page starts after the key of the last value in the
+// previous page. This is faster and more consistent than offset pagination on
+// large tables, as it does not need a total and new rows do not shift pages.
+// A page shorter than args.Limit is the last one, after which io.EOF is
+// returned. Errs from args.Fetch are returned as-is, and the same page is
+// tried again on the next read.
+//
+// Example:
+//
+//	r := NewKeysetReader(
+//	    NewKeysetReaderArgs[user, int]{
+//	        Fetch: func(ctx context.Context, after int, limit int) ([]user, error) {
+//	            // SELECT ... WHERE id > $after ORDER BY id LIMIT $limit
+//	            return queryUsers(ctx, after, limit)
+//	        },
+//	        Key:   func(u user) int { return u.ID },
+//	        Limit: 500,
+//	    },
+//	)
+func NewKeysetReader[T any, K comparable](args NewKeysetReaderArgs[T, K]) core.Reader[[]T] {
+	if args.Fetch == nil || args.Key == nil {
+		return core.ReaderImpl[[]T]{}
+	}
+	if args.Limit <= 0 {
+		args.Limit = 100
+	}
+
+	after := args.Start
+	done := false
+
+	return core.ReaderImpl[[]T]{
+		Impl: func(ctx context.Context) (vals []T, err error) {
+			if done {
+				return nil, io.EOF
+			}
+
+			vals, err = args.Fetch(ctx, after, args.Limit)
+			if err != nil {
+				return nil, err
+			}
+
+			done = len(vals) < args.Limit
+			if len(vals) == 0 {
+				return nil, io.EOF
+			}
+
+			after = args.Key(vals[len(vals)-1])
+			return vals, nil
+		},
+	}
+}
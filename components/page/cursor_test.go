@@ -0,0 +1,127 @@
+package page
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func tfReadAll[T any](ctx context.Context, r core.Reader[T]) ([]T, error) {
+	var v T
+	var s = make([]T, 0, 8)
+	var err error
+
+	for v, err = r.Read(ctx); err == nil; v, err = r.Read(ctx) {
+		s = append(s, v)
+	}
+
+	return s, err
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewCursorReader.
+// -----------------------------------------------------------------------------
+
+func TestNewCursorReaderIdeal(t *testing.T) {
+	pages := map[string][]int{"": {1, 2}, "a": {}, "b": {3}}
+	nexts := map[string]string{"": "a", "a": "b", "b": ""}
+
+	r := NewCursorReader(
+		NewCursorReaderArgs[int, string]{
+			Fetch: func(ctx context.Context, cursor string) ([]int, string, error) {
+				return pages[cursor], nexts[cursor], nil
+			},
+		},
+	)
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", [][]int{{1, 2}, {3}}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewCursorReaderWithFetchErr(t *testing.T) {
+	calls := 0
+	r := NewCursorReader(
+		NewCursorReaderArgs[int, int]{
+			Fetch: func(ctx context.Context, cursor int) ([]int, int, error) {
+				calls++
+				if calls == 1 {
+					return nil, 0, io.ErrUnexpectedEOF
+				}
+
+				return []int{cursor}, 0, nil
+			},
+			Start: 7,
+		},
+	)
+
+	_, err := r.Read(context.Background())
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+
+	vals, err := r.Read(context.Background())
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{7}, vals, func(s string) { t.Fatal(s) })
+
+	_, err = r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewCursorReaderWithNilFetch(t *testing.T) {
+	_, err := NewCursorReader(NewCursorReaderArgs[int, string]{}).Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewKeysetReader.
+// -----------------------------------------------------------------------------
+
+func TestNewKeysetReaderIdeal(t *testing.T) {
+	for _, n := range []int{5, 6} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			afters := make([]int, 0, 4)
+			r := NewKeysetReader(
+				NewKeysetReaderArgs[int, int]{
+					Fetch: func(ctx context.Context, after int, limit int) ([]int, error) {
+						afters = append(afters, after)
+
+						vals := make([]int, 0, limit)
+						for i := after + 1; i <= n && len(vals) < limit; i++ {
+							vals = append(vals, i)
+						}
+
+						return vals, nil
+					},
+					Key:   func(v int) int { return v },
+					Limit: 3,
+				},
+			)
+
+			vals, err := tfReadAll(context.Background(), core.NewReaderWithUnbatching(r))
+			assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+			assertEq("len", n, len(vals), func(s string) { t.Fatal(s) })
+
+			want := []int{0, 3}
+			if n == 6 {
+				want = append(want, 6) // Full last page needs one more fetch.
+			}
+
+			assertEq("afters", want, afters, func(s string) { t.Fatal(s) })
+		})
+	}
+}
+
+func TestNewKeysetReaderWithNilKey(t *testing.T) {
+	r := NewKeysetReader(
+		NewKeysetReaderArgs[int, int]{
+			Fetch: func(ctx context.Context, after int, limit int) ([]int, error) {
+				return []int{1}, nil
+			},
+		},
+	)
+
+	_, err := r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,158 @@
+package page
+
+import (
+	"context"
+	"io"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// Limiter limits the rate of fetches in NewFetchReader. It is satisfied by
+// e.g *rate.Limiter from golang.org/x/time/rate.
+type Limiter interface {
+	// Wait blocks until a fetch is allowed, or returns an err if ctx is done.
+	Wait(ctx context.Context) error
+}
+
+type NewFetchReaderArgs[T any] struct {
+	// Reader gives the pages which are fetched, e.g NewOnceReader. On nil,
+	// the func returns core.ReaderImpl[Paged[[]T]].
+	Reader core.Reader[Page]
+	// Fetch fetches the values of a page. It is called concurrently. On nil,
+	// the func returns core.ReaderImpl[Paged[[]T]].
+	Fetch func(ctx context.Context, p Page) ([]T, error)
+	// Workers is the max number of concurrent fetches. On <= 0, defaults to 1.
+	Workers int
+	// Limiter is waited on before each fetch. On nil, fetches are not limited.
+	Limiter Limiter
+}
+
+type fetchResult[T any] struct {
+	vals []T
+	err  error
+}
+
+type fetchFuture[T any] struct {
+	page   Page
+	ch     chan fetchResult[T] // Buffered, such that fetches never block.
+	cancel context.CancelFunc
+}
+
+// NewFetchReader returns a Reader which fetches pages from args.Reader with
+// args.Fetch, up to args.Workers at a time. Pages are read ahead as needed,
+// while the values are returned in the same order as the pages came from
+// args.Reader, so the output is identical to fetching pages one at a time.
+// io.EOF is returned when args.Reader is exhausted and all pages are fetched.
+//
+// Fetches are given a ctx owned by the reader, which carries the values (but
+// not the cancellation) of the ctx of the read which started them. When a
+// fetch (or args.Limiter, or args.Reader) gives an err, then all outstanding
+// fetches are cancelled and the err is returned, as well as on all subsequent
+// reads. If the ctx of a read is done while it waits, then ctx.Err() is
+// returned and the fetch is kept for the next read, so reads may use
+// different ctxs, e.g one per request. Note that outstanding fetches are not
+// cancelled if the reader is abandoned before io.EOF or an err.
+//
+// Example:
+//
+//	r := NewFetchReader(
+//	    NewFetchReaderArgs[user]{
+//	        Reader: NewOnceReader(NewOnceReaderArgs{Total: 10_000, Limit: 100}),
+//	        Fetch: func(ctx context.Context, p Page) ([]user, error) {
+//	            return myClient.ListUsers(ctx, p.Skip, p.Limit)
+//	        },
+//	        Workers: 8,
+//	        Limiter: rate.NewLimiter(rate.Limit(20), 1),
+//	    },
+//	)
+func NewFetchReader[T any](args NewFetchReaderArgs[T]) core.Reader[Paged[[]T]] {
+	if args.Reader == nil || args.Fetch == nil {
+		return core.ReaderImpl[Paged[[]T]]{}
+	}
+	if args.Workers <= 0 {
+		args.Workers = 1
+	}
+
+	queue := make([]*fetchFuture[T], 0, args.Workers)
+	eof := false
+	fail := error(nil)
+
+	start := func(ctx context.Context, p Page) *fetchFuture[T] {
+		ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f := &fetchFuture[T]{page: p, ch: make(chan fetchResult[T], 1), cancel: cancel}
+
+		go func() {
+			if args.Limiter != nil {
+				if err := args.Limiter.Wait(ctx); err != nil {
+					f.ch <- fetchResult[T]{err: err}
+					return
+				}
+			}
+
+			vals, err := args.Fetch(ctx, p)
+			f.ch <- fetchResult[T]{vals: vals, err: err}
+		}()
+
+		return f
+	}
+
+	return core.ReaderImpl[Paged[[]T]]{
+		Impl: func(ctx context.Context) (val Paged[[]T], err error) {
+			if fail != nil {
+				return val, fail
+			}
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			for !eof && len(queue) < args.Workers {
+				p, err := args.Reader.Read(ctx)
+				if err != nil && ctx.Err() != nil {
+					break // Not sticky, may be retried with another ctx.
+				}
+				if err != nil {
+					eof = true
+					if err != io.EOF {
+						f := &fetchFuture[T]{ch: make(chan fetchResult[T], 1), cancel: func() {}}
+						f.ch <- fetchResult[T]{err: err}
+						queue = append(queue, f)
+					}
+
+					break
+				}
+
+				queue = append(queue, start(ctx, p))
+			}
+
+			if len(queue) == 0 && eof {
+				return val, io.EOF
+			}
+			if len(queue) == 0 {
+				return val, ctx.Err()
+			}
+
+			var res fetchResult[T]
+			select {
+			case <-ctx.Done():
+				return val, ctx.Err()
+			case res = <-queue[0].ch:
+			}
+
+			f := queue[0]
+			f.cancel()
+			queue = queue[1:]
+
+			if res.err != nil {
+				for _, f := range queue {
+					f.cancel()
+				}
+
+				queue = nil
+				fail = res.err
+				return val, fail
+			}
+
+			return Paged[[]T]{Page: f.page, Val: res.vals}, nil
+		},
+	}
+}
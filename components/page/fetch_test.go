@@ -0,0 +1,171 @@
+package page
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type tfLimiter struct{ n atomic.Int64 }
+
+func (l *tfLimiter) Wait(ctx context.Context) error {
+	l.n.Add(1)
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewFetchReader.
+// -----------------------------------------------------------------------------
+
+func TestNewFetchReaderIdeal(t *testing.T) {
+	mx := sync.Mutex{}
+	active := 0
+	maxActive := 0
+	limiter := &tfLimiter{}
+
+	r := NewFetchReader(
+		NewFetchReaderArgs[int]{
+			Reader: NewOnceReader(NewOnceReaderArgs{Total: 10, Limit: 2}),
+			Fetch: func(ctx context.Context, p Page) ([]int, error) {
+				mx.Lock()
+				active++
+				maxActive = max(maxActive, active)
+				mx.Unlock()
+
+				// Later pages finish first.
+				time.Sleep(time.Millisecond * time.Duration(10-p.Skip))
+
+				mx.Lock()
+				active--
+				mx.Unlock()
+
+				return []int{p.Skip, p.Skip + 1}, nil
+			},
+			Workers: 3,
+			Limiter: limiter,
+		},
+	)
+
+	pages, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("len", 5, len(pages), func(s string) { t.Fatal(s) })
+
+	for i, p := range pages {
+		assertEq("skip", i*2, p.Skip, func(s string) { t.Fatal(s) })
+		assertEq("vals", []int{i * 2, i*2 + 1}, p.Val, func(s string) { t.Fatal(s) })
+	}
+
+	if maxActive < 2 || maxActive > 3 {
+		t.Fatalf("unexpected max concurrent fetches: %v", maxActive)
+	}
+
+	assertEq("limiter", int64(5), limiter.n.Load(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewFetchReaderWithFetchErr(t *testing.T) {
+	canceled := atomic.Int64{}
+
+	r := NewFetchReader(
+		NewFetchReaderArgs[int]{
+			Reader: NewOnceReader(NewOnceReaderArgs{Total: 10, Limit: 1}),
+			Fetch: func(ctx context.Context, p Page) ([]int, error) {
+				if p.Skip == 1 {
+					return nil, io.ErrUnexpectedEOF
+				}
+				if p.Skip == 0 {
+					return []int{0}, nil
+				}
+
+				<-ctx.Done()
+				canceled.Add(1)
+				return nil, ctx.Err()
+			},
+			Workers: 4,
+		},
+	)
+
+	p, err := r.Read(context.Background())
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{0}, p.Val, func(s string) { t.Fatal(s) })
+
+	_, err = r.Read(context.Background())
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+
+	_, err = r.Read(context.Background())
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+
+	// Pages 2, 3 and 4 were in flight.
+	for i := 0; i < 100 && canceled.Load() < 3; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	assertEq("canceled", int64(3), canceled.Load(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewFetchReaderWithCtxDone(t *testing.T) {
+	release := make(chan struct{})
+	r := NewFetchReader(
+		NewFetchReaderArgs[int]{
+			Reader: NewOnceReader(NewOnceReaderArgs{Total: 1, Limit: 1}),
+			Fetch: func(ctx context.Context, p Page) ([]int, error) {
+				<-release
+				return []int{1}, nil
+			},
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := r.Read(ctx)
+	assertEq("err", context.Canceled, err, func(s string) { t.Fatal(s) })
+
+	close(release)
+	p, err := r.Read(context.Background())
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1}, p.Val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewFetchReaderWithCtxDoneAndFreshCtx(t *testing.T) {
+	release := make(chan struct{})
+	r := NewFetchReader(
+		NewFetchReaderArgs[int]{
+			Reader: NewOnceReader(NewOnceReaderArgs{Total: 2, Limit: 1}),
+			Fetch: func(ctx context.Context, p Page) ([]int, error) {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-release:
+					return []int{p.Skip}, nil
+				}
+			},
+			Workers: 2,
+		},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*5)
+	defer cancel()
+
+	_, err := r.Read(ctx)
+	assertEq("err", context.DeadlineExceeded, err, func(s string) { t.Fatal(s) })
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		p, err := r.Read(context.Background())
+		assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+		assertEq("vals", []int{i}, p.Val, func(s string) { t.Fatal(s) })
+	}
+
+	_, err = r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewFetchReaderWithNilFetch(t *testing.T) {
+	r := NewFetchReader(NewFetchReaderArgs[int]{Reader: NewOnceReader(NewOnceReaderArgs{})})
+
+	_, err := r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
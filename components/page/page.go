@@ -28,6 +28,8 @@ type NewOnceReaderArgs struct {
 // NewOnceReader returns a Reader of pagination directives which supports
 // paging from 0 to 'total' with the given 'limit', then returns an io.EOF.
 // It is useful for e.g paging through a database if you know the total size.
+// A 'limit' <= 0 gives io.EOF immediately, see NewAutoReader if the total size
+// is not known.
 //
 // Examples (interactive):
 //   - https://go.dev/play/p/NOuwlVmJwbg
@@ -36,7 +38,7 @@ func NewOnceReader(args NewOnceReaderArgs) core.Reader[Page] {
 
 	return core.ReaderImpl[Page]{
 		Impl: func(ctx context.Context) (p Page, err error) {
-			if skip >= args.Total {
+			if skip >= args.Total || args.Limit <= 0 {
 				return p, io.EOF
 			}
 
@@ -83,6 +83,13 @@ func TestNewOnceReaderIdealOdd(t *testing.T) {
 	assertEq("total", 0, val.Total, func(s string) { t.Fatal(s) })
 }
 
+func TestNewOnceReaderWithZeroLimit(t *testing.T) {
+	pr := NewOnceReader(NewOnceReaderArgs{Total: 4, Limit: 0})
+
+	_, err := pr.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
 // -----------------------------------------------------------------------------
 // Tests: NewContReader.
 // -----------------------------------------------------------------------------
@@ -0,0 +1,182 @@
+package page
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+var (
+	// ErrPageDuplicate is given by NewReassemblyWriter when a page is written
+	// more than once, or when it overlaps with another page.
+	ErrPageDuplicate = errors.New("page: duplicate page")
+	// ErrPageGap is given by NewReassemblyWriter on Close when some pages are
+	// missing, or when the Limit of a page is <= 0.
+	ErrPageGap = errors.New("page: gap between pages")
+)
+
+type NewReassemblyWriterArgs[T any] struct {
+	// Writer receives pages in skip order, as soon as they are contiguous.
+	Writer core.Writer[Paged[T]]
+	// WriterAll receives all values in skip order, once. This is done when
+	// Page.Total is reached (if it is > 0), or on Close. Note that values
+	// are held in memory until then.
+	WriterAll core.Writer[[]T]
+	// Start is the Skip of the first page.
+	Start int
+}
+
+// NewReassemblyWriter returns a WriteCloser which accepts pages in any order,
+// e.g from parallel producers using NewOnceWriter, and reassembles them in skip
+// order: the page following a page with Skip s is the one with Skip s + Limit.
+// Pages are written to args.Writer as soon as all pages before them are seen,
+// and the full dataset is written to args.WriterAll, see
+// NewReassemblyWriterArgs. At least one of the two should be set, else this
+// func simply returns a core.WriteCloserImpl.
+//
+// Writing a page which was already written (or which overlaps with another)
+// gives an err wrapping ErrPageDuplicate. Close gives an err wrapping ErrPageGap
+// if pages are missing, in which case args.WriterAll is not written to. Writes
+// after Close give io.ErrClosedPipe. Errs from args.Writer and args.WriterAll
+// are returned as-is, pages (and values for args.WriterAll) which were not
+// written because of an err are kept and retried on the next write or Close,
+// so a Close which gave such an err may be called again. The returned
+// WriteCloser is safe for concurrent use.
+//
+// Example:
+//
+//	w := NewReassemblyWriter(
+//	    NewReassemblyWriterArgs[[]int]{
+//	        WriterAll: core.WriterImpl[[][]int]{
+//	            Impl: func(ctx context.Context, pages [][]int) error {
+//	                fmt.Println(pages) // [[1 2] [3 4] [5]]
+//	                return nil
+//	            },
+//	        },
+//	    },
+//	)
+//
+//	w.Write(ctx, Paged[[]int]{Page{Skip: 2, Limit: 2, Total: 5}, []int{3, 4}})
+//	w.Write(ctx, Paged[[]int]{Page{Skip: 0, Limit: 2, Total: 5}, []int{1, 2}})
+//	w.Write(ctx, Paged[[]int]{Page{Skip: 4, Limit: 1, Total: 5}, []int{5}})
+func NewReassemblyWriter[T any](args NewReassemblyWriterArgs[T]) core.WriteCloser[Paged[T]] {
+	if args.Writer == nil && args.WriterAll == nil {
+		return core.WriteCloserImpl[Paged[T]]{}
+	}
+
+	mx := sync.Mutex{}
+	next := args.Start
+	pending := make(map[int]Paged[T])
+	all := make([]T, 0, 8)
+	allDone := false
+	closed := false
+	closeDone := false
+
+	flushAll := func(ctx context.Context) error {
+		if args.WriterAll == nil || allDone {
+			return nil
+		}
+		if err := args.WriterAll.Write(ctx, all); err != nil {
+			return err
+		}
+
+		allDone = true
+		return nil
+	}
+
+	// drain writes pending pages which are contiguous with those written.
+	drain := func(ctx context.Context) error {
+		for {
+			p, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			// Kept in pending on err, such that it is retried.
+			if args.Writer != nil {
+				if err := args.Writer.Write(ctx, p); err != nil {
+					return err
+				}
+			}
+
+			delete(pending, next)
+			next += p.Limit
+
+			if args.WriterAll != nil {
+				all = append(all, p.Val)
+			}
+		}
+
+		for skip := range pending {
+			if skip < next {
+				// Starts within a written page, so it is unreachable.
+				delete(pending, skip)
+				return fmt.Errorf("%w: skip %d", ErrPageDuplicate, skip)
+			}
+		}
+
+		return nil
+	}
+
+	return core.WriteCloserImpl[Paged[T]]{
+		ImplC: func() (err error) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			if closeDone {
+				return nil
+			}
+
+			closed = true
+			if err = drain(context.Background()); err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				skips := make([]int, 0, len(pending))
+				for skip := range pending {
+					skips = append(skips, skip)
+				}
+
+				sort.Ints(skips)
+				return fmt.Errorf("%w: want skip %d, have %v", ErrPageGap, next, skips)
+			}
+
+			if err = flushAll(context.Background()); err != nil {
+				return err
+			}
+
+			closeDone = true
+			return nil
+		},
+		ImplW: func(ctx context.Context, p Paged[T]) (err error) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			if closed {
+				return io.ErrClosedPipe
+			}
+			if p.Limit <= 0 {
+				return fmt.Errorf("%w: limit %d at skip %d", ErrPageGap, p.Limit, p.Skip)
+			}
+			if _, ok := pending[p.Skip]; ok || p.Skip < next {
+				return fmt.Errorf("%w: skip %d", ErrPageDuplicate, p.Skip)
+			}
+
+			pending[p.Skip] = p
+			if err = drain(ctx); err != nil {
+				return err
+			}
+
+			if p.Total > 0 && next >= p.Total {
+				return flushAll(ctx)
+			}
+
+			return nil
+		},
+	}
+}
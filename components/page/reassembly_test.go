@@ -0,0 +1,192 @@
+package page
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// -----------------------------------------------------------------------------
+// Tests: NewReassemblyWriter.
+// -----------------------------------------------------------------------------
+
+func TestNewReassemblyWriterIdeal(t *testing.T) {
+	rw := core.NewReadWriterFrom[Paged[[]int]]()
+	rwAll := core.NewReadWriterFrom[[][]int]()
+
+	w := NewReassemblyWriter(NewReassemblyWriterArgs[[]int]{Writer: rw, WriterAll: rwAll})
+	ctx := context.Background()
+
+	err := w.Write(ctx, Paged[[]int]{Page{Skip: 2, Limit: 2, Total: 5}, []int{3, 4}})
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	// Nothing before skip 0 is seen.
+	_, err = rw.Read(ctx)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+
+	w.Write(ctx, Paged[[]int]{Page{Skip: 0, Limit: 2, Total: 5}, []int{1, 2}})
+	w.Write(ctx, Paged[[]int]{Page{Skip: 4, Limit: 1, Total: 5}, []int{5}})
+
+	pages, _ := tfReadAll(ctx, rw)
+	assertEq("len", 3, len(pages), func(s string) { t.Fatal(s) })
+	for i, skip := range []int{0, 2, 4} {
+		assertEq("skip", skip, pages[i].Skip, func(s string) { t.Fatal(s) })
+	}
+
+	// Written when Total was reached, not on Close.
+	all, err := rwAll.Read(ctx)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("all", [][]int{{1, 2}, {3, 4}, {5}}, all, func(s string) { t.Fatal(s) })
+
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+	_, err = rwAll.Read(ctx)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+
+	err = w.Write(ctx, Paged[[]int]{Page{Skip: 5, Limit: 1}, nil})
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReassemblyWriterWithConcurrentWriters(t *testing.T) {
+	rwAll := core.NewReadWriterFrom[[]int]()
+	w := NewReassemblyWriter(NewReassemblyWriterArgs[int]{WriterAll: rwAll})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for skip := i; skip < 100; skip += 4 {
+				w.Write(context.Background(), Paged[int]{Page{Skip: skip, Limit: 1}, skip})
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+
+	all, _ := rwAll.Read(context.Background())
+	assertEq("len", 100, len(all), func(s string) { t.Fatal(s) })
+	for i, v := range all {
+		assertEq("val", i, v, func(s string) { t.Fatal(s) })
+	}
+}
+
+func TestNewReassemblyWriterWithDuplicate(t *testing.T) {
+	w := NewReassemblyWriter(NewReassemblyWriterArgs[int]{Writer: core.NewReadWriterFrom[Paged[int]]()})
+	ctx := context.Background()
+
+	w.Write(ctx, Paged[int]{Page{Skip: 0, Limit: 2}, 1})
+	err := w.Write(ctx, Paged[int]{Page{Skip: 0, Limit: 2}, 1})
+	if !errors.Is(err, ErrPageDuplicate) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// Overlaps with the page at skip 0.
+	w.Write(ctx, Paged[int]{Page{Skip: 3, Limit: 1}, 1})
+	err = w.Write(ctx, Paged[int]{Page{Skip: 1, Limit: 1}, 1})
+	if !errors.Is(err, ErrPageDuplicate) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	w.Write(ctx, Paged[int]{Page{Skip: 2, Limit: 1}, 1})
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewReassemblyWriterWithGap(t *testing.T) {
+	rwAll := core.NewReadWriterFrom[[]int]()
+	w := NewReassemblyWriter(NewReassemblyWriterArgs[int]{WriterAll: rwAll})
+
+	w.Write(context.Background(), Paged[int]{Page{Skip: 0, Limit: 1}, 1})
+	w.Write(context.Background(), Paged[int]{Page{Skip: 2, Limit: 1}, 3})
+
+	if err := w.Close(); !errors.Is(err, ErrPageGap) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	_, err := rwAll.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReassemblyWriterWithNilWriters(t *testing.T) {
+	w := NewReassemblyWriter(NewReassemblyWriterArgs[int]{})
+
+	err := w.Write(context.Background(), Paged[int]{Page{Limit: 1}, 1})
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReassemblyWriterWithWriteErr(t *testing.T) {
+	fail := true
+	pages := make([]int, 0, 4)
+	rwAll := core.NewReadWriterFrom[[]int]()
+
+	w := NewReassemblyWriter(
+		NewReassemblyWriterArgs[int]{
+			Writer: core.WriterImpl[Paged[int]]{
+				Impl: func(ctx context.Context, p Paged[int]) error {
+					if fail {
+						return io.ErrUnexpectedEOF
+					}
+
+					pages = append(pages, p.Val)
+					return nil
+				},
+			},
+			WriterAll: rwAll,
+		},
+	)
+
+	ctx := context.Background()
+	err := w.Write(ctx, Paged[int]{Page{Skip: 0, Limit: 1}, 1})
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+
+	// Page 0 is retried here, before page 1.
+	fail = false
+	err = w.Write(ctx, Paged[int]{Page{Skip: 1, Limit: 1}, 2})
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("pages", []int{1, 2}, pages, func(s string) { t.Fatal(s) })
+
+	fail = true
+	w.Write(ctx, Paged[int]{Page{Skip: 2, Limit: 1}, 3})
+
+	err = w.Close()
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+
+	fail = false
+	err = w.Close()
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("pages", []int{1, 2, 3}, pages, func(s string) { t.Fatal(s) })
+
+	all, _ := rwAll.Read(ctx)
+	assertEq("all", []int{1, 2, 3}, all, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReassemblyWriterWithWriteAllErr(t *testing.T) {
+	fail := true
+	all := make([][]int, 0, 2)
+
+	w := NewReassemblyWriter(
+		NewReassemblyWriterArgs[int]{
+			WriterAll: core.WriterImpl[[]int]{
+				Impl: func(ctx context.Context, s []int) error {
+					if fail {
+						return io.ErrUnexpectedEOF
+					}
+
+					all = append(all, s)
+					return nil
+				},
+			},
+		},
+	)
+
+	err := w.Write(context.Background(), Paged[int]{Page{Skip: 0, Limit: 1, Total: 1}, 1})
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+
+	fail = false
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+	assertEq("all", [][]int{{1}}, all, func(s string) { t.Fatal(s) })
+}
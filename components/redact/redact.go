@@ -0,0 +1,305 @@
+package redact
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DefaultNames are (normalized) field names which are redacted by default,
+// see NewFmtArgs.Names.
+var DefaultNames = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"apikey",
+	"authorization",
+	"cookie",
+	"session",
+	"email",
+	"ssn",
+	"creditcard",
+	"cardnumber",
+}
+
+// maxDepth guards against cyclic values.
+const maxDepth = 32
+
+type NewFmtArgs struct {
+	// Names are field names which are redacted even if they are not tagged.
+	// Names are matched case-insensitively and without '_' and '-', if they
+	// are contained in a field name, e.g "token" matches "refresh_token". Map
+	// keys are matched too. On nil, defaults to DefaultNames, use an empty
+	// non-nil slice for disabling name based redaction.
+	Names []string
+	// Mask is what redacted values are replaced with. On "", defaults to "***".
+	Mask string
+	// Salt is prepended to values before they are hashed, making it harder
+	// to guess hashed values from a list of candidates.
+	Salt string
+}
+
+// NewFmt returns a func which converts values into a redacted form, intended
+// to be used as the Fmt func of e.g log.NewStreamedReaderArgs or (with U as
+// any) stats.NewStreamedTeeReaderArgs.
+//
+// Structs become map[string]any (keyed by json tag name if set, else the field
+// name), slices and arrays become []any, maps become map[string]any, pointers
+// and interfaces are dereferenced. time.Time is kept as-is. Values implementing
+// json.Marshaler (e.g json.RawMessage) are marshaled, decoded and then walked
+// like any other value, such that e.g a "password" key within them is still
+// redacted. Values implementing encoding.TextMarshaler become their text.
+// Unexported fields are skipped. Struct fields may be tagged with the
+// following:
+//
+//	`gtl:"redact"` // Value is replaced with args.Mask.
+//	`gtl:"hash"`   // Value is replaced with a (salted) sha256 hash, which is
+//	               // stable for equal values, also behind pointers.
+//	`gtl:"keep"`   // Value is kept, even if its name is in args.Names.
+//	`gtl:"-"`      // Field is omitted.
+//
+// Example:
+//
+//	type user struct {
+//	    Name  string
+//	    Email string `gtl:"hash"`
+//	    Token string
+//	}
+//
+//	f := NewFmt[user](NewFmtArgs{})
+//	f(user{"a", "a@b.c", "x"}) // map[Email:sha256:... Name:a Token:***]
+//
+// Note that redaction is based on names only, the contents of strings (such as
+// a token within a URL, or the text of a TextMarshaler) are not inspected.
+func NewFmt[T any](args NewFmtArgs) func(T) any {
+	if args.Names == nil {
+		args.Names = DefaultNames
+	}
+	if args.Mask == "" {
+		args.Mask = "***"
+	}
+
+	names := make([]string, 0, len(args.Names))
+	for _, name := range args.Names {
+		if name = normalize(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	r := redactor{names: names, mask: args.Mask, salt: args.Salt}
+	return func(v T) any {
+		return r.walk(reflect.ValueOf(v), 0)
+	}
+}
+
+// Fmt is NewFmt with default args.
+func Fmt[T any](v T) any {
+	return NewFmt[T](NewFmtArgs{})(v)
+}
+
+func normalize(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "_", "")
+	s = strings.ReplaceAll(s, "-", "")
+	return s
+}
+
+type redactor struct {
+	names []string
+	mask  string
+	salt  string
+}
+
+func (r redactor) sensitive(name string) bool {
+	name = normalize(name)
+	for _, n := range r.names {
+		if strings.Contains(name, n) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hash hashes the value of 'v', pointers and interfaces are dereferenced such
+// that equal values give equal hashes. Nil gives nil.
+func (r redactor) hash(v reflect.Value) any {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	s := ""
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.String:
+		s = v.String()
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		s = fmt.Sprint(v.Interface())
+	default:
+		b, _ := json.Marshal(r.walk(v, 0))
+		s = string(b)
+	}
+
+	h := sha256.Sum256([]byte(r.salt + s))
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+var (
+	typeTime          = reflect.TypeOf(time.Time{})
+	typeJSONMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// marshaled handles values implementing json.Marshaler or
+// encoding.TextMarshaler, see NewFmt. The bool is false for other values.
+func (r redactor) marshaled(v reflect.Value, depth int) (any, bool) {
+	t := v.Type()
+	if t.Kind() == reflect.Interface {
+		return nil, false
+	}
+	if !t.Implements(typeJSONMarshaler) && !t.Implements(typeTextMarshaler) {
+		return nil, false
+	}
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, true
+	}
+
+	if m, ok := v.Interface().(json.Marshaler); ok {
+		b, err := m.MarshalJSON()
+		if err != nil {
+			return r.mask, true
+		}
+
+		var x any
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		if err := d.Decode(&x); err != nil {
+			return r.mask, true
+		}
+
+		return r.walk(reflect.ValueOf(x), depth+1), true
+	}
+
+	b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return r.mask, true
+	}
+
+	return string(b), true
+}
+
+func (r redactor) walk(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxDepth {
+		return r.mask
+	}
+
+	t := v.Type()
+	if t == typeTime {
+		return v.Interface()
+	}
+	if x, ok := r.marshaled(v, depth); ok {
+		return x
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return r.walk(v.Elem(), depth+1)
+
+	case reflect.Struct:
+		m := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			name := f.Name
+			if tag, _, _ := strings.Cut(f.Tag.Get("json"), ","); tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+
+			switch f.Tag.Get("gtl") {
+			case "-":
+			case "redact":
+				m[name] = r.mask
+			case "hash":
+				m[name] = r.hash(v.Field(i))
+			case "keep":
+				m[name] = r.walk(v.Field(i), depth+1)
+			default:
+				if r.sensitive(f.Name) || r.sensitive(name) {
+					m[name] = r.mask
+					continue
+				}
+
+				m[name] = r.walk(v.Field(i), depth+1)
+			}
+		}
+
+		return m
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k := fmt.Sprint(iter.Key().Interface())
+			if r.sensitive(k) {
+				m[k] = r.mask
+				continue
+			}
+
+			m[k] = r.walk(iter.Value(), depth+1)
+		}
+
+		return m
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return v.Interface() // []byte, kept as-is.
+		}
+
+		s := make([]any, v.Len())
+		for i := range s {
+			s[i] = r.walk(v.Index(i), depth+1)
+		}
+
+		return s
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+
+	default:
+		return v.Interface()
+	}
+}
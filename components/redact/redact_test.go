@@ -0,0 +1,165 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func assertEq[T any](subject string, want T, have T, f func(string)) {
+	if f == nil {
+		return
+	}
+
+	ab, _ := json.Marshal(want)
+	bb, _ := json.Marshal(have)
+
+	as := string(ab)
+	bs := string(bb)
+
+	if as == bs {
+		return
+	}
+
+	s := "unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n"
+	f(fmt.Sprintf(s, subject, as, bs))
+}
+
+func tfHash(salt string, v any) string {
+	h := sha256.Sum256([]byte(salt + fmt.Sprint(v)))
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+type tvAddr struct {
+	Street string
+	Phone  string `gtl:"redact"`
+}
+
+type tvUser struct {
+	Name     string
+	Email    string `gtl:"hash"`
+	Password string
+	APIKey   string `json:"api_key"`
+	Session  string `gtl:"keep"`
+	Internal string `gtl:"-"`
+	Skipped  string `json:"-"`
+	Addr     *tvAddr
+	Tags     []string
+	Meta     map[string]any
+	private  string
+}
+
+func TestNewFmtIdeal(t *testing.T) {
+	f := NewFmt[tvUser](NewFmtArgs{})
+	have := f(
+		tvUser{
+			Name:     "a",
+			Email:    "a@b.c",
+			Password: "x",
+			APIKey:   "x",
+			Session:  "s",
+			Internal: "x",
+			Skipped:  "x",
+			Addr:     &tvAddr{Street: "st", Phone: "123"},
+			Tags:     []string{"t"},
+			Meta:     map[string]any{"refresh_token": "x", "n": 1},
+			private:  "x",
+		},
+	)
+
+	want := map[string]any{
+		"Name":     "a",
+		"Email":    tfHash("", "a@b.c"),
+		"Password": "***",
+		"api_key":  "***",
+		"Session":  "s",
+		"Addr":     map[string]any{"Street": "st", "Phone": "***"},
+		"Tags":     []any{"t"},
+		"Meta":     map[string]any{"refresh_token": "***", "n": 1},
+	}
+
+	assertEq("val", any(want), have, func(s string) { t.Fatal(s) })
+}
+
+func TestNewFmtWithArgs(t *testing.T) {
+	f := NewFmt[tvUser](NewFmtArgs{Names: []string{"Street"}, Mask: "-", Salt: "salt"})
+	have := f(tvUser{Email: "a@b.c", Password: "x", Addr: &tvAddr{Street: "st"}})
+
+	want := map[string]any{
+		"Name":     "",
+		"Email":    tfHash("salt", "a@b.c"),
+		"Password": "x",
+		"api_key":  "",
+		"Session":  "",
+		"Addr":     map[string]any{"Street": "-", "Phone": "-"},
+		"Tags":     nil,
+		"Meta":     nil,
+	}
+
+	assertEq("val", any(want), have, func(s string) { t.Fatal(s) })
+}
+
+func TestNewFmtWithNonStructs(t *testing.T) {
+	ts := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	assertEq("time", any(ts), Fmt(ts), func(s string) { t.Fatal(s) })
+	assertEq("int", any(1), Fmt(1), func(s string) { t.Fatal(s) })
+	assertEq("nil", any(nil), Fmt[*tvUser](nil), func(s string) { t.Fatal(s) })
+	assertEq("bytes", any([]byte("a")), Fmt([]byte("a")), func(s string) { t.Fatal(s) })
+
+	have := Fmt([]map[string]string{{"token": "x", "a": "b"}})
+	want := []any{map[string]any{"token": "***", "a": "b"}}
+	assertEq("slice", any(want), have, func(s string) { t.Fatal(s) })
+}
+
+func TestNewFmtWithCycle(t *testing.T) {
+	type node struct{ Next *node }
+
+	n := &node{}
+	n.Next = n
+
+	// Should not overflow the stack.
+	Fmt(n)
+}
+
+func TestNewFmtWithHashedPointer(t *testing.T) {
+	type user struct {
+		Email *string `gtl:"hash"`
+		Addr  *tvAddr `gtl:"hash"`
+	}
+
+	a, b := "a@b.c", "a@b.c"
+	f := NewFmt[user](NewFmtArgs{})
+
+	x := f(user{Email: &a, Addr: &tvAddr{Street: "st"}}).(map[string]any)
+	y := f(user{Email: &b, Addr: &tvAddr{Street: "st"}}).(map[string]any)
+	assertEq[any]("email", tfHash("", "a@b.c"), x["Email"], func(s string) { t.Fatal(s) })
+	assertEq("email", x["Email"], y["Email"], func(s string) { t.Fatal(s) })
+	assertEq("addr", x["Addr"], y["Addr"], func(s string) { t.Fatal(s) })
+
+	z := f(user{}).(map[string]any)
+	assertEq[any]("nil", nil, z["Email"], func(s string) { t.Fatal(s) })
+}
+
+type tvMarshaler struct{ secret string }
+
+func (m tvMarshaler) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"password": m.secret, "a": "b"})
+}
+
+func TestNewFmtWithMarshalers(t *testing.T) {
+	type payload struct {
+		Raw    json.RawMessage
+		Custom tvMarshaler
+	}
+
+	have := Fmt(payload{Raw: json.RawMessage(`{"token":"x","n":1}`), Custom: tvMarshaler{"x"}})
+	want := map[string]any{
+		"Raw":    map[string]any{"token": "***", "n": 1},
+		"Custom": map[string]any{"password": "***", "a": "b"},
+	}
+
+	assertEq("val", any(want), have, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,124 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+type NewWriterArgs[T any, K comparable] struct {
+	// Key is used to pick a route for each value written to the returned
+	// Writer. On nil, all values are written to Default.
+	Key func(T) K
+	// Writers are static routes, keyed by what Key returns. These are not
+	// closed when the returned WriteCloser is closed.
+	Writers map[K]core.Writer[T]
+	// Factory lazily creates writers for keys which are not in Writers. The
+	// created writers are reused for subsequent values with the same key, and
+	// are closed (if they implement io.Closer) when the returned WriteCloser
+	// is closed. Errors coming from here are returned as-is from Write. On nil,
+	// values without a static route are written to Default.
+	Factory func(K) (core.Writer[T], error)
+	// Default receives values which have no other route. On nil, such values
+	// give an io.ErrClosedPipe.
+	Default core.Writer[T]
+}
+
+// NewWriter returns a WriteCloser which dispatches each value to one of
+// several writers, based on the key returned by args.Key. Values are written
+// to the first route found in the following order: args.Writers, writers
+// created with args.Factory, and args.Default. See args for details.
+//
+// Errs from the routes are returned as-is; note that an io.ErrClosedPipe from
+// a single route will normally stop the whole pipeline, e.g with eventloop.New.
+// Closing the returned WriteCloser closes all writers made by args.Factory,
+// errs are combined with errors.Join. Writes after Close give io.ErrClosedPipe.
+// The returned WriteCloser is safe for concurrent use as long as the routes are.
+//
+// Example:
+//
+//	type event struct { Kind string }
+//
+//	w := NewWriter(
+//	    NewWriterArgs[event, string]{
+//	        Key: func(e event) string { return e.Kind },
+//	        Factory: func(kind string) (core.Writer[event], error) {
+//	            // E.g open a file named after 'kind' and wrap it with
+//	            // core.NewWriterFromValues.
+//	        },
+//	    },
+//	)
+//	defer w.Close()
+func NewWriter[T any, K comparable](args NewWriterArgs[T, K]) core.WriteCloser[T] {
+	var mx sync.Mutex
+	var closed bool
+	created := make(map[K]core.Writer[T])
+
+	route := func(val T) (w core.Writer[T], err error) {
+		mx.Lock()
+		defer mx.Unlock()
+
+		if closed {
+			return nil, io.ErrClosedPipe
+		}
+		if args.Key == nil {
+			return args.Default, nil
+		}
+
+		k := args.Key(val)
+		if w, ok := args.Writers[k]; ok && w != nil {
+			return w, nil
+		}
+		if w, ok := created[k]; ok {
+			return w, nil
+		}
+		if args.Factory == nil {
+			return args.Default, nil
+		}
+
+		w, err = args.Factory(k)
+		if err != nil {
+			return nil, err
+		}
+		if w == nil {
+			return args.Default, nil
+		}
+
+		created[k] = w
+		return w, nil
+	}
+
+	return core.WriteCloserImpl[T]{
+		ImplC: func() (err error) {
+			mx.Lock()
+			defer mx.Unlock()
+
+			if closed {
+				return
+			}
+
+			closed = true
+			for _, w := range created {
+				if c, ok := w.(io.Closer); ok {
+					err = errors.Join(err, c.Close())
+				}
+			}
+
+			return
+		},
+		ImplW: func(ctx context.Context, val T) (err error) {
+			w, err := route(val)
+			if err != nil {
+				return
+			}
+			if w == nil {
+				return io.ErrClosedPipe
+			}
+
+			return w.Write(ctx, val)
+		},
+	}
+}
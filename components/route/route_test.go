@@ -0,0 +1,156 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+var tvErr = errors.New("test error")
+
+func assertEq[T any](subject string, want T, have T, f func(string)) {
+	if f == nil {
+		return
+	}
+
+	ab, _ := json.Marshal(want)
+	bb, _ := json.Marshal(have)
+
+	as := string(ab)
+	bs := string(bb)
+
+	if as == bs {
+		return
+	}
+
+	s := "unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n"
+	f(fmt.Sprintf(s, subject, as, bs))
+}
+
+func tfNewSliceWriteCloser[T any](s *[]T, closed *int) core.WriteCloser[T] {
+	return core.WriteCloserImpl[T]{
+		ImplC: func() error {
+			*closed++
+			return nil
+		},
+		ImplW: func(ctx context.Context, v T) error {
+			*s = append(*s, v)
+			return nil
+		},
+	}
+}
+
+func tfKey(v int) string {
+	if v%2 == 0 {
+		return "even"
+	}
+
+	return "odd"
+}
+
+func TestNewWriterIdeal(t *testing.T) {
+	ctx := context.Background()
+
+	evens := make([]int, 0, 2)
+	odds := make([]int, 0, 2)
+	closed := 0
+
+	w := NewWriter(
+		NewWriterArgs[int, string]{
+			Key:     tfKey,
+			Writers: map[string]core.Writer[int]{"even": tfNewSliceWriteCloser(&evens, &closed)},
+			Factory: func(k string) (core.Writer[int], error) {
+				return tfNewSliceWriteCloser(&odds, &closed), nil
+			},
+		},
+	)
+
+	for _, v := range []int{1, 2, 3, 4} {
+		err := w.Write(ctx, v)
+		assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	}
+
+	assertEq("evens", []int{2, 4}, evens, func(s string) { t.Fatal(s) })
+	assertEq("odds", []int{1, 3}, odds, func(s string) { t.Fatal(s) })
+
+	// Only the created writer is closed.
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+	assertEq("closed", 1, closed, func(s string) { t.Fatal(s) })
+
+	err := w.Write(ctx, 5)
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithDefault(t *testing.T) {
+	ctx := context.Background()
+
+	evens := make([]int, 0, 2)
+	other := make([]int, 0, 2)
+	closed := 0
+
+	w := NewWriter(
+		NewWriterArgs[int, string]{
+			Key:     tfKey,
+			Writers: map[string]core.Writer[int]{"even": tfNewSliceWriteCloser(&evens, &closed)},
+			Default: tfNewSliceWriteCloser(&other, &closed),
+		},
+	)
+
+	for _, v := range []int{1, 2, 3} {
+		err := w.Write(ctx, v)
+		assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	}
+
+	assertEq("evens", []int{2}, evens, func(s string) { t.Fatal(s) })
+	assertEq("other", []int{1, 3}, other, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithNoRoute(t *testing.T) {
+	w := NewWriter(NewWriterArgs[int, string]{Key: tfKey})
+
+	err := w.Write(context.Background(), 1)
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithFactoryErr(t *testing.T) {
+	w := NewWriter(
+		NewWriterArgs[int, string]{
+			Key: tfKey,
+			Factory: func(k string) (core.Writer[int], error) {
+				return nil, tvErr
+			},
+		},
+	)
+
+	err := w.Write(context.Background(), 1)
+	assertEq("err", tvErr.Error(), err.Error(), func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithFactoryReuse(t *testing.T) {
+	n := 0
+	s := make([]int, 0, 2)
+	closed := 0
+
+	w := NewWriter(
+		NewWriterArgs[int, string]{
+			Key: tfKey,
+			Factory: func(k string) (core.Writer[int], error) {
+				n++
+				return tfNewSliceWriteCloser(&s, &closed), nil
+			},
+		},
+	)
+
+	w.Write(context.Background(), 1)
+	w.Write(context.Background(), 3)
+	w.Write(context.Background(), 2)
+	assertEq("created", 2, n, func(s string) { t.Fatal(s) })
+
+	w.Close()
+	assertEq("closed", 2, closed, func(s string) { t.Fatal(s) })
+}
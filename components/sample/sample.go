@@ -0,0 +1,331 @@
+package sample
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// -----------------------------------------------------------------------------
+// Bernoulli.
+// -----------------------------------------------------------------------------
+
+type NewBernoulliReaderArgs[T any] struct {
+	// Reader is what the func reads from. On nil, the func simply returns
+	// a core.ReaderImpl[T], making it pointless.
+	Reader core.Reader[T]
+	// P is the probability of keeping a value. On <= 0, no values are kept,
+	// on >= 1, all values are kept.
+	P float64
+	// Rand is used for deciding which values to keep; use a seeded one for
+	// reproducible samples. On nil, a new one seeded with the time is used.
+	Rand *rand.Rand
+}
+
+// NewBernoulliReader returns a Reader which reads from args.Reader and keeps
+// each value with probability args.P, discarding the rest. See args for details.
+//
+// Example:
+//
+//	r := NewBernoulliReader(
+//	    NewBernoulliReaderArgs[int]{
+//	        Reader: core.NewReaderFrom(1, 2, 3, 4, 5, 6, 7, 8),
+//	        P:      0.5,
+//	        Rand:   rand.New(rand.NewSource(1)),
+//	    },
+//	)
+func NewBernoulliReader[T any](args NewBernoulliReaderArgs[T]) core.Reader[T] {
+	if args.Reader == nil {
+		return core.ReaderImpl[T]{}
+	}
+	if args.Rand == nil {
+		args.Rand = newRand()
+	}
+
+	return core.ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			for {
+				val, err = args.Reader.Read(ctx)
+				if err != nil || args.Rand.Float64() < args.P {
+					return
+				}
+			}
+		},
+	}
+}
+
+type NewBernoulliWriterArgs[T any] struct {
+	// Writer is what the returned Writer writes to. On nil, the func simply
+	// returns a core.WriterImpl[T], making it pointless.
+	Writer core.Writer[T]
+	// P is the probability of keeping a value. On <= 0, no values are kept,
+	// on >= 1, all values are kept.
+	P float64
+	// Rand is used for deciding which values to keep; use a seeded one for
+	// reproducible samples. On nil, a new one seeded with the time is used.
+	Rand *rand.Rand
+}
+
+// NewBernoulliWriter returns a Writer which writes each value into args.Writer
+// with probability args.P; the rest are discarded (nil err). See args for details.
+func NewBernoulliWriter[T any](args NewBernoulliWriterArgs[T]) core.Writer[T] {
+	if args.Writer == nil {
+		return core.WriterImpl[T]{}
+	}
+	if args.Rand == nil {
+		args.Rand = newRand()
+	}
+
+	return core.WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			if args.Rand.Float64() >= args.P {
+				return
+			}
+
+			return args.Writer.Write(ctx, val)
+		},
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Every Nth.
+// -----------------------------------------------------------------------------
+
+type NewNthReaderArgs[T any] struct {
+	// Reader is what the func reads from. On nil, the func simply returns
+	// a core.ReaderImpl[T], making it pointless.
+	Reader core.Reader[T]
+	// N defines which values to keep, i.e every Nth. On <= 0, defaults to 1.
+	N int
+}
+
+// NewNthReader returns a Reader which reads from args.Reader and keeps every
+// Nth value, starting with the first, discarding the rest. See args for details.
+//
+// Example:
+//
+//	r := NewNthReader(NewNthReaderArgs[int]{Reader: core.NewReaderFrom(1, 2, 3, 4), N: 2})
+//
+//	t.Log(r.Read(nil)) // 1, nil
+//	t.Log(r.Read(nil)) // 3, nil
+//	t.Log(r.Read(nil)) // 0, io.EOF
+func NewNthReader[T any](args NewNthReaderArgs[T]) core.Reader[T] {
+	if args.Reader == nil {
+		return core.ReaderImpl[T]{}
+	}
+	if args.N <= 0 {
+		args.N = 1
+	}
+
+	i := 0
+	return core.ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			for {
+				val, err = args.Reader.Read(ctx)
+				if err != nil {
+					return
+				}
+
+				i++
+				if (i-1)%args.N == 0 {
+					return
+				}
+			}
+		},
+	}
+}
+
+type NewNthWriterArgs[T any] struct {
+	// Writer is what the returned Writer writes to. On nil, the func simply
+	// returns a core.WriterImpl[T], making it pointless.
+	Writer core.Writer[T]
+	// N defines which values to keep, i.e every Nth. On <= 0, defaults to 1.
+	N int
+}
+
+// NewNthWriter returns a Writer which writes every Nth value into args.Writer,
+// starting with the first; the rest are discarded (nil err). See args for details.
+func NewNthWriter[T any](args NewNthWriterArgs[T]) core.Writer[T] {
+	if args.Writer == nil {
+		return core.WriterImpl[T]{}
+	}
+	if args.N <= 0 {
+		args.N = 1
+	}
+
+	i := 0
+	return core.WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			i++
+			if (i-1)%args.N != 0 {
+				return
+			}
+
+			return args.Writer.Write(ctx, val)
+		},
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Reservoir.
+// -----------------------------------------------------------------------------
+
+// reservoir implements "algorithm R", i.e a uniform sample of 'k' values.
+type reservoir[T any] struct {
+	k   int
+	n   int
+	buf []T
+	rng *rand.Rand
+}
+
+func (r *reservoir[T]) add(v T) {
+	r.n++
+	if len(r.buf) < r.k {
+		r.buf = append(r.buf, v)
+		return
+	}
+
+	if j := r.rng.Intn(r.n); j < r.k {
+		r.buf[j] = v
+	}
+}
+
+type NewReservoirReaderArgs[T any] struct {
+	// Reader is what the func reads from. On nil, the func simply returns
+	// a core.ReaderImpl[T], making it pointless.
+	Reader core.Reader[T]
+	// K is the sample size. On <= 0, defaults to 8.
+	K int
+	// Rand is used for deciding which values to keep; use a seeded one for
+	// reproducible samples. On nil, a new one seeded with the time is used.
+	Rand *rand.Rand
+}
+
+// NewReservoirReader returns a Reader which yields a uniform random sample of
+// (at most) args.K values from args.Reader. Note that the first read consumes
+// args.Reader completely, i.e until io.EOF, before the sample is returned one
+// value at a time. Errs other than io.EOF are returned as-is, and the values
+// which were sampled up to that point are kept, such that the next read
+// continues sampling where the err happened.
+//
+// Example:
+//
+//	r := NewReservoirReader(
+//	    NewReservoirReaderArgs[int]{
+//	        Reader: core.NewReaderFrom(1, 2, 3, 4, 5, 6, 7, 8),
+//	        K:      2,
+//	        Rand:   rand.New(rand.NewSource(1)),
+//	    },
+//	)
+//
+//	t.Log(r.Read(nil)) // ?, nil
+//	t.Log(r.Read(nil)) // ?, nil
+//	t.Log(r.Read(nil)) // 0, io.EOF
+func NewReservoirReader[T any](args NewReservoirReaderArgs[T]) core.Reader[T] {
+	if args.Reader == nil {
+		return core.ReaderImpl[T]{}
+	}
+	if args.K <= 0 {
+		args.K = 8
+	}
+	if args.Rand == nil {
+		args.Rand = newRand()
+	}
+
+	var buf []T
+	done := false
+	r := reservoir[T]{k: args.K, buf: make([]T, 0, args.K), rng: args.Rand}
+
+	return core.ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			if !done {
+				for {
+					val, err = args.Reader.Read(ctx)
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						return *new(T), err
+					}
+
+					r.add(val)
+				}
+
+				done = true
+				buf = r.buf
+			}
+
+			if len(buf) == 0 {
+				return *new(T), io.EOF
+			}
+
+			val = buf[0]
+			buf = buf[1:]
+			return val, nil
+		},
+	}
+}
+
+type NewReservoirWriterArgs[T any] struct {
+	// Writer is where the sample is written when the returned WriteCloser is
+	// closed. On nil, the func simply returns a core.WriteCloserImpl[T].
+	Writer core.Writer[T]
+	// K is the sample size. On <= 0, defaults to 8.
+	K int
+	// Rand is used for deciding which values to keep; use a seeded one for
+	// reproducible samples. On nil, a new one seeded with the time is used.
+	Rand *rand.Rand
+}
+
+// NewReservoirWriter returns a WriteCloser which keeps a uniform random sample
+// of (at most) args.K of the values written to it. Nothing is written to
+// args.Writer until Close is called, at which point the sample is written in
+// one go; errs from args.Writer stop that process and are returned from Close.
+// Writes after Close give io.ErrClosedPipe.
+func NewReservoirWriter[T any](args NewReservoirWriterArgs[T]) core.WriteCloser[T] {
+	if args.Writer == nil {
+		return core.WriteCloserImpl[T]{}
+	}
+	if args.K <= 0 {
+		args.K = 8
+	}
+	if args.Rand == nil {
+		args.Rand = newRand()
+	}
+
+	r := reservoir[T]{k: args.K, buf: make([]T, 0, args.K), rng: args.Rand}
+	closed := false
+
+	return core.WriteCloserImpl[T]{
+		ImplC: func() (err error) {
+			if closed {
+				return
+			}
+
+			closed = true
+			for _, v := range r.buf {
+				err = args.Writer.Write(context.Background(), v)
+				if err != nil {
+					return
+				}
+			}
+
+			return
+		},
+		ImplW: func(ctx context.Context, val T) (err error) {
+			if closed {
+				return io.ErrClosedPipe
+			}
+
+			r.add(val)
+			return
+		},
+	}
+}
@@ -0,0 +1,235 @@
+package sample
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func assertEq[T any](subject string, want T, have T, f func(string)) {
+	if f == nil {
+		return
+	}
+
+	ab, _ := json.Marshal(want)
+	bb, _ := json.Marshal(have)
+
+	as := string(ab)
+	bs := string(bb)
+
+	if as == bs {
+		return
+	}
+
+	s := "unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n"
+	f(fmt.Sprintf(s, subject, as, bs))
+}
+
+func tfRange(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+
+	return s
+}
+
+func tfReadAll[T any](ctx context.Context, r core.Reader[T]) ([]T, error) {
+	var v T
+	var s = make([]T, 0, 8)
+	var err error
+
+	for v, err = r.Read(ctx); err == nil; v, err = r.Read(ctx) {
+		s = append(s, v)
+	}
+
+	return s, err
+}
+
+func tfWriteSlice[T any](ctx context.Context, s []T, w core.Writer[T]) error {
+	err := *new(error)
+	for _, v := range s {
+		err = w.Write(ctx, v)
+		if err != nil {
+			break
+		}
+	}
+
+	return err
+}
+
+// -----------------------------------------------------------------------------
+// Tests: Bernoulli.
+// -----------------------------------------------------------------------------
+
+func TestNewBernoulliReaderIdeal(t *testing.T) {
+	newReader := func() core.Reader[int] {
+		return NewBernoulliReader(
+			NewBernoulliReaderArgs[int]{
+				Reader: core.NewReaderFrom(tfRange(1000)...),
+				P:      0.1,
+				Rand:   rand.New(rand.NewSource(1)),
+			},
+		)
+	}
+
+	a, err := tfReadAll(context.Background(), newReader())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+
+	b, err := tfReadAll(context.Background(), newReader())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("reproducible", a, b, func(s string) { t.Fatal(s) })
+
+	if len(a) < 50 || len(a) > 150 {
+		t.Fatalf("unexpected sample size: %v", len(a))
+	}
+}
+
+func TestNewBernoulliReaderWithNilReader(t *testing.T) {
+	r := NewBernoulliReader(NewBernoulliReaderArgs[int]{P: 1})
+
+	_, err := r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewBernoulliWriterIdeal(t *testing.T) {
+	rw := core.NewReadWriterFrom[int]()
+	w := NewBernoulliWriter(
+		NewBernoulliWriterArgs[int]{
+			Writer: rw,
+			P:      1,
+			Rand:   rand.New(rand.NewSource(1)),
+		},
+	)
+
+	err := tfWriteSlice(context.Background(), tfRange(3), w)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	vals, _ := tfReadAll(context.Background(), core.Reader[int](rw))
+	assertEq("vals", tfRange(3), vals, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests: Nth.
+// -----------------------------------------------------------------------------
+
+func TestNewNthReaderIdeal(t *testing.T) {
+	r := NewNthReader(NewNthReaderArgs[int]{Reader: core.NewReaderFrom(tfRange(7)...), N: 3})
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{0, 3, 6}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewNthReaderWithNilReader(t *testing.T) {
+	r := NewNthReader(NewNthReaderArgs[int]{N: 3})
+
+	_, err := r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewNthWriterIdeal(t *testing.T) {
+	rw := core.NewReadWriterFrom[int]()
+	w := NewNthWriter(NewNthWriterArgs[int]{Writer: rw, N: 3})
+
+	err := tfWriteSlice(context.Background(), tfRange(7), w)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	vals, _ := tfReadAll(context.Background(), core.Reader[int](rw))
+	assertEq("vals", []int{0, 3, 6}, vals, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests: Reservoir.
+// -----------------------------------------------------------------------------
+
+func TestNewReservoirReaderIdeal(t *testing.T) {
+	newReader := func() core.Reader[int] {
+		return NewReservoirReader(
+			NewReservoirReaderArgs[int]{
+				Reader: core.NewReaderFrom(tfRange(100)...),
+				K:      5,
+				Rand:   rand.New(rand.NewSource(1)),
+			},
+		)
+	}
+
+	a, err := tfReadAll(context.Background(), newReader())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("len", 5, len(a), func(s string) { t.Fatal(s) })
+
+	b, _ := tfReadAll(context.Background(), newReader())
+	assertEq("reproducible", a, b, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReservoirReaderWithShortReader(t *testing.T) {
+	r := NewReservoirReader(
+		NewReservoirReaderArgs[int]{
+			Reader: core.NewReaderFrom(1, 2),
+			K:      5,
+		},
+	)
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReservoirReaderWithReadErr(t *testing.T) {
+	i := 0
+	r := NewReservoirReader(
+		NewReservoirReaderArgs[int]{
+			Reader: core.ReaderImpl[int]{
+				Impl: func(ctx context.Context) (int, error) {
+					i++
+					switch {
+					case i == 3:
+						return 0, io.ErrUnexpectedEOF
+					case i > 5:
+						return 0, io.EOF
+					}
+
+					return i, nil
+				},
+			},
+			K: 5,
+		},
+	)
+
+	_, err := r.Read(context.Background())
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+
+	// Values before the err are kept.
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2, 4, 5}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReservoirWriterIdeal(t *testing.T) {
+	rw := core.NewReadWriterFrom[int]()
+	w := NewReservoirWriter(
+		NewReservoirWriterArgs[int]{
+			Writer: rw,
+			K:      5,
+			Rand:   rand.New(rand.NewSource(1)),
+		},
+	)
+
+	err := tfWriteSlice(context.Background(), tfRange(100), w)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	// Nothing is written before close.
+	_, err = rw.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+	assertEq("err", io.ErrClosedPipe, w.Write(context.Background(), 1), func(s string) { t.Fatal(s) })
+
+	vals, _ := tfReadAll(context.Background(), core.Reader[int](rw))
+	assertEq("len", 5, len(vals), func(s string) { t.Fatal(s) })
+}
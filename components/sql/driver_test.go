@@ -0,0 +1,145 @@
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// This file contains a fake database/sql driver, such that no real database
+// is needed for tests.
+
+type tfQuery struct {
+	query string
+	args  []driver.Value
+}
+
+type tfDB struct {
+	mx        sync.Mutex
+	query     func(query string, args []driver.Value) (cols []string, rows [][]driver.Value, err error)
+	exec      func(query string, args []driver.Value) error
+	queries   []tfQuery
+	execs     []tfQuery
+	commits   int
+	rollbacks int
+	openRows  int
+}
+
+func tfNewDB(db *tfDB) *stdsql.DB {
+	return stdsql.OpenDB(tfConnector{db: db})
+}
+
+type tfConnector struct{ db *tfDB }
+
+func (c tfConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &tfConn{db: c.db}, nil
+}
+
+func (c tfConnector) Driver() driver.Driver { return tfDriver{} }
+
+type tfDriver struct{}
+
+func (tfDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("use tfNewDB")
+}
+
+type tfConn struct{ db *tfDB }
+
+func (c *tfConn) Prepare(query string) (driver.Stmt, error) {
+	return &tfStmt{db: c.db, query: query}, nil
+}
+
+func (c *tfConn) Close() error { return nil }
+
+func (c *tfConn) Begin() (driver.Tx, error) { return &tfTx{db: c.db}, nil }
+
+type tfTx struct{ db *tfDB }
+
+func (tx *tfTx) Commit() error {
+	tx.db.mx.Lock()
+	defer tx.db.mx.Unlock()
+
+	tx.db.commits++
+	return nil
+}
+
+func (tx *tfTx) Rollback() error {
+	tx.db.mx.Lock()
+	defer tx.db.mx.Unlock()
+
+	tx.db.rollbacks++
+	return nil
+}
+
+type tfStmt struct {
+	db    *tfDB
+	query string
+}
+
+func (s *tfStmt) Close() error { return nil }
+
+func (s *tfStmt) NumInput() int { return -1 }
+
+func (s *tfStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mx.Lock()
+	s.db.execs = append(s.db.execs, tfQuery{query: s.query, args: args})
+	s.db.mx.Unlock()
+
+	if s.db.exec != nil {
+		if err := s.db.exec(s.query, args); err != nil {
+			return nil, err
+		}
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *tfStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mx.Lock()
+	s.db.queries = append(s.db.queries, tfQuery{query: s.query, args: args})
+	s.db.mx.Unlock()
+
+	if s.db.query == nil {
+		return nil, errors.New("no query func")
+	}
+
+	cols, rows, err := s.db.query(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	s.db.mx.Lock()
+	s.db.openRows++
+	s.db.mx.Unlock()
+
+	return &tfRows{db: s.db, cols: cols, rows: rows}, nil
+}
+
+type tfRows struct {
+	db   *tfDB
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *tfRows) Columns() []string { return r.cols }
+
+func (r *tfRows) Close() error {
+	r.db.mx.Lock()
+	defer r.db.mx.Unlock()
+
+	r.db.openRows--
+	return nil
+}
+
+func (r *tfRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
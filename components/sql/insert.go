@@ -0,0 +1,297 @@
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// Beginner begins transactions, it is implemented by *sql.DB and *sql.Conn.
+type Beginner interface {
+	BeginTx(ctx context.Context, opts *stdsql.TxOptions) (*stdsql.Tx, error)
+}
+
+// Placeholder is a style of query placeholders, which depends on the database.
+type Placeholder int
+
+const (
+	// PlaceholderQuestion is "?", used by e.g MySQL and SQLite.
+	PlaceholderQuestion Placeholder = iota
+	// PlaceholderDollar is "$1", "$2", ..., used by e.g Postgres.
+	PlaceholderDollar
+	// PlaceholderAtP is "@p1", "@p2", ..., used by e.g SQL Server.
+	PlaceholderAtP
+	// PlaceholderColon is ":1", ":2", ..., used by e.g Oracle.
+	PlaceholderColon
+)
+
+// format returns placeholder number 'i', starting at 1.
+func (p Placeholder) format(i int) string {
+	switch p {
+	case PlaceholderDollar:
+		return "$" + strconv.Itoa(i)
+	case PlaceholderAtP:
+		return "@p" + strconv.Itoa(i)
+	case PlaceholderColon:
+		return ":" + strconv.Itoa(i)
+	default:
+		return "?"
+	}
+}
+
+// OnConflictDoUpdate returns an upsert suffix for NewInsertWriterArgs.Suffix,
+// as supported by e.g Postgres and SQLite:
+//
+//	ON CONFLICT (c1, c2) DO UPDATE SET u1 = EXCLUDED.u1, u2 = EXCLUDED.u2
+//
+// If 'update' is empty, then the suffix is "ON CONFLICT (c1, c2) DO NOTHING".
+func OnConflictDoUpdate(conflict []string, update []string) string {
+	b := strings.Builder{}
+	b.WriteString("ON CONFLICT (")
+	b.WriteString(strings.Join(conflict, ", "))
+	b.WriteString(")")
+
+	if len(update) == 0 {
+		b.WriteString(" DO NOTHING")
+		return b.String()
+	}
+
+	b.WriteString(" DO UPDATE SET ")
+	for i, col := range update {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(col + " = EXCLUDED." + col)
+	}
+
+	return b.String()
+}
+
+// OnDuplicateKeyUpdate returns an upsert suffix for NewInsertWriterArgs.Suffix,
+// as supported by MySQL:
+//
+//	ON DUPLICATE KEY UPDATE u1 = VALUES(u1), u2 = VALUES(u2)
+func OnDuplicateKeyUpdate(update []string) string {
+	b := strings.Builder{}
+	b.WriteString("ON DUPLICATE KEY UPDATE ")
+	for i, col := range update {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(col + " = VALUES(" + col + ")")
+	}
+
+	return b.String()
+}
+
+type NewInsertWriterArgs[T any] struct {
+	// DB begins a transaction per batch. On nil, the func returns
+	// core.WriteCloserImpl[[]T].
+	DB Beginner
+	// Table is inserted into. On "", the func returns core.WriteCloserImpl[[]T].
+	Table string
+	// Columns are inserted into. On nil, defaults to the column names of the
+	// fields of T (see NewRowsReader), in field order.
+	Columns []string
+	// Values returns the values of a T, in the same order as Columns. On nil,
+	// values are taken from the fields of T which match Columns. If T is not
+	// a struct, or if there is a column without a field, then the func
+	// returns core.WriteCloserImpl[[]T].
+	Values func(T) []any
+	// Placeholder is the placeholder style of the database. Defaults to
+	// PlaceholderQuestion.
+	Placeholder Placeholder
+	// Suffix is appended to each INSERT statement, e.g for upserts, see
+	// OnConflictDoUpdate and OnDuplicateKeyUpdate.
+	Suffix string
+	// MaxParams is the max number of placeholders in a single statement, some
+	// databases have such a limit. Batches which would exceed it are split
+	// into several statements in the same transaction. On <= 0, there is no
+	// limit.
+	MaxParams int
+	// TxOptions are given to DB.BeginTx.
+	TxOptions *stdsql.TxOptions
+}
+
+// NewInsertWriter returns a WriteCloser which inserts batches of values into
+// args.Table, as multi-row INSERT statements:
+//
+//	INSERT INTO table (c1, c2) VALUES (?, ?), (?, ?), ... [args.Suffix]
+//
+// Each batch is written in its own transaction, which is committed if all
+// statements succeed and rolled back otherwise, such that batches are either
+// written completely or not at all. Errs are returned as-is (joined with errs
+// from rolling back). Empty batches are ignored. Close does not close args.DB,
+// but makes subsequent writes give io.ErrClosedPipe. The returned WriteCloser
+// is safe for concurrent use. It pairs well with core.NewWriterWithBatching.
+//
+// Example:
+//
+//	w := NewInsertWriter(
+//	    NewInsertWriterArgs[user]{
+//	        DB:          db,
+//	        Table:       "users",
+//	        Placeholder: PlaceholderDollar,
+//	        Suffix:      OnConflictDoUpdate([]string{"id"}, []string{"name"}),
+//	    },
+//	)
+//	defer w.Close()
+//
+//	// Used as e.g the Writer in eventloop.New.
+//	bw := core.NewWriterWithBatching[user](w, 500)
+func NewInsertWriter[T any](args NewInsertWriterArgs[T]) core.WriteCloser[[]T] {
+	if args.DB == nil || args.Table == "" {
+		return core.WriteCloserImpl[[]T]{}
+	}
+	if args.Columns == nil || args.Values == nil {
+		t := reflect.TypeOf((*T)(nil)).Elem()
+		if t.Kind() != reflect.Struct {
+			return core.WriteCloserImpl[[]T]{}
+		}
+
+		fields := dbFields(t)
+		if args.Columns == nil {
+			args.Columns = make([]string, 0, len(fields))
+			for _, f := range fields {
+				args.Columns = append(args.Columns, f.name)
+			}
+		}
+		if args.Values == nil {
+			values, ok := newValuesFunc[T](fields, args.Columns)
+			if !ok {
+				return core.WriteCloserImpl[[]T]{}
+			}
+
+			args.Values = values
+		}
+	}
+	if len(args.Columns) == 0 {
+		return core.WriteCloserImpl[[]T]{}
+	}
+
+	// Rows per statement.
+	rows := 0
+	if args.MaxParams > 0 {
+		rows = max(1, args.MaxParams/len(args.Columns))
+	}
+
+	prefix := "INSERT INTO " + args.Table + " (" + strings.Join(args.Columns, ", ") + ") VALUES "
+	build := func(batch []T) (string, []any) {
+		b := strings.Builder{}
+		b.WriteString(prefix)
+
+		params := make([]any, 0, len(batch)*len(args.Columns))
+		for i, v := range batch {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+
+			b.WriteString("(")
+			for j, param := range args.Values(v) {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+
+				params = append(params, param)
+				b.WriteString(args.Placeholder.format(len(params)))
+			}
+			b.WriteString(")")
+		}
+
+		if args.Suffix != "" {
+			b.WriteString(" ")
+			b.WriteString(args.Suffix)
+		}
+
+		return b.String(), params
+	}
+
+	mx := sync.RWMutex{}
+	closed := false
+
+	return core.WriteCloserImpl[[]T]{
+		ImplC: func() error {
+			mx.Lock()
+			defer mx.Unlock()
+
+			closed = true
+			return nil
+		},
+		ImplW: func(ctx context.Context, batch []T) (err error) {
+			mx.RLock()
+			defer mx.RUnlock()
+
+			if closed {
+				return io.ErrClosedPipe
+			}
+			if len(batch) == 0 {
+				return nil
+			}
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			tx, err := args.DB.BeginTx(ctx, args.TxOptions)
+			if err != nil {
+				return err
+			}
+
+			for len(batch) > 0 {
+				n := len(batch)
+				if rows > 0 {
+					n = min(n, rows)
+				}
+
+				query, params := build(batch[:n])
+				if _, err = tx.ExecContext(ctx, query, params...); err != nil {
+					return errors.Join(err, tx.Rollback())
+				}
+
+				batch = batch[n:]
+			}
+
+			return tx.Commit()
+		},
+	}
+}
+
+// newValuesFunc returns a func which gives the values of the fields of T
+// which match 'cols'. The bool is false if a column has no matching field.
+// Fields behind nil pointers to embedded structs give nil, i.e NULL.
+func newValuesFunc[T any](fields []dbField, cols []string) (func(T) []any, bool) {
+	byName := make(map[string][]int, len(fields))
+	for _, f := range fields {
+		byName[strings.ToLower(f.name)] = f.index
+	}
+
+	indexes := make([][]int, len(cols))
+	for i, col := range cols {
+		index, ok := byName[strings.ToLower(col)]
+		if !ok {
+			return nil, false
+		}
+
+		indexes[i] = index
+	}
+
+	return func(v T) []any {
+		rv := reflect.ValueOf(v)
+		vals := make([]any, len(indexes))
+		for i, index := range indexes {
+			if f, err := rv.FieldByIndexErr(index); err == nil {
+				vals[i] = f.Interface()
+			}
+		}
+
+		return vals
+	}, true
+}
@@ -0,0 +1,154 @@
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// -----------------------------------------------------------------------------
+// Tests: NewInsertWriter.
+// -----------------------------------------------------------------------------
+
+func TestNewInsertWriterIdeal(t *testing.T) {
+	db := &tfDB{}
+	w := NewInsertWriter(
+		NewInsertWriterArgs[tvUser]{
+			DB:          tfNewDB(db),
+			Table:       "users",
+			Placeholder: PlaceholderDollar,
+			Suffix:      OnConflictDoUpdate([]string{"id"}, []string{"user_name"}),
+		},
+	)
+
+	err := w.Write(context.Background(), []tvUser{{ID: 1, Name: "a", Email: "x"}, {ID: 2, Name: "b"}})
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	want := "INSERT INTO users (id, user_name, Email) VALUES ($1, $2, $3), ($4, $5, $6) " +
+		"ON CONFLICT (id) DO UPDATE SET user_name = EXCLUDED.user_name"
+
+	assertEq("len", 1, len(db.execs), func(s string) { t.Fatal(s) })
+	assertEq("query", want, db.execs[0].query, func(s string) { t.Fatal(s) })
+	assertEq("args", []driver.Value{int64(1), "a", "x", int64(2), "b", ""}, db.execs[0].args, func(s string) { t.Fatal(s) })
+	assertEq("commits", 1, db.commits, func(s string) { t.Fatal(s) })
+
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+	err = w.Write(context.Background(), []tvUser{{}})
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewInsertWriterWithMaxParams(t *testing.T) {
+	db := &tfDB{}
+	w := NewInsertWriter(
+		NewInsertWriterArgs[tvUser]{
+			DB:        tfNewDB(db),
+			Table:     "users",
+			Columns:   []string{"id"},
+			MaxParams: 2,
+		},
+	)
+
+	// Via batching, as it would be used.
+	bw := core.NewWriterWithBatching[tvUser](w, 5)
+	for i := 1; i <= 5; i++ {
+		bw.Write(context.Background(), tvUser{ID: int64(i)})
+	}
+
+	assertEq("len", 3, len(db.execs), func(s string) { t.Fatal(s) })
+	assertEq("query", "INSERT INTO users (id) VALUES (?), (?)", db.execs[0].query, func(s string) { t.Fatal(s) })
+	assertEq("query", "INSERT INTO users (id) VALUES (?)", db.execs[2].query, func(s string) { t.Fatal(s) })
+	assertEq("commits", 1, db.commits, func(s string) { t.Fatal(s) })
+}
+
+func TestNewInsertWriterWithExecErr(t *testing.T) {
+	errExec := errors.New("exec")
+	db := &tfDB{
+		exec: func(query string, args []driver.Value) error {
+			if args[0] == int64(3) {
+				return errExec
+			}
+
+			return nil
+		},
+	}
+
+	w := NewInsertWriter(
+		NewInsertWriterArgs[tvUser]{
+			DB:        tfNewDB(db),
+			Table:     "users",
+			MaxParams: 3,
+			Values:    func(u tvUser) []any { return []any{u.ID, u.Name, u.Email} },
+		},
+	)
+
+	err := w.Write(context.Background(), []tvUser{{ID: 1}, {ID: 2}, {ID: 3}})
+	if !errors.Is(err, errExec) {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	assertEq("commits", 0, db.commits, func(s string) { t.Fatal(s) })
+	assertEq("rollbacks", 1, db.rollbacks, func(s string) { t.Fatal(s) })
+}
+
+func TestNewInsertWriterWithValues(t *testing.T) {
+	db := &tfDB{}
+	w := NewInsertWriter(
+		NewInsertWriterArgs[int]{
+			DB:      tfNewDB(db),
+			Table:   "nums",
+			Columns: []string{"n"},
+			Values:  func(n int) []any { return []any{n} },
+			Suffix:  OnDuplicateKeyUpdate([]string{"n"}),
+		},
+	)
+
+	w.Write(context.Background(), []int{1})
+	want := "INSERT INTO nums (n) VALUES (?) ON DUPLICATE KEY UPDATE n = VALUES(n)"
+	assertEq("query", want, db.execs[0].query, func(s string) { t.Fatal(s) })
+}
+
+func TestNewInsertWriterWithEmbeddedPointer(t *testing.T) {
+	type Meta struct {
+		Email string
+	}
+	type user struct {
+		ID int64 `db:"id"`
+		*Meta
+	}
+
+	db := &tfDB{}
+	w := NewInsertWriter(NewInsertWriterArgs[user]{DB: tfNewDB(db), Table: "users"})
+
+	err := w.Write(context.Background(), []user{{ID: 1}, {ID: 2, Meta: &Meta{Email: "x"}}})
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("args", []driver.Value{int64(1), nil, int64(2), "x"}, db.execs[0].args, func(s string) { t.Fatal(s) })
+}
+
+func TestNewInsertWriterWithUnknownColumn(t *testing.T) {
+	w := NewInsertWriter(
+		NewInsertWriterArgs[tvUser]{
+			DB:      tfNewDB(&tfDB{}),
+			Table:   "users",
+			Columns: []string{"nope"},
+		},
+	)
+
+	err := w.Write(context.Background(), []tvUser{{}})
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestPlaceholderFormat(t *testing.T) {
+	assertEq("question", "?", PlaceholderQuestion.format(2), func(s string) { t.Fatal(s) })
+	assertEq("dollar", "$2", PlaceholderDollar.format(2), func(s string) { t.Fatal(s) })
+	assertEq("atp", "@p2", PlaceholderAtP.format(2), func(s string) { t.Fatal(s) })
+	assertEq("colon", ":2", PlaceholderColon.format(2), func(s string) { t.Fatal(s) })
+}
+
+func TestOnConflictDoUpdateWithoutUpdate(t *testing.T) {
+	have := OnConflictDoUpdate([]string{"a", "b"}, nil)
+	assertEq("suffix", "ON CONFLICT (a, b) DO NOTHING", have, func(s string) { t.Fatal(s) })
+}
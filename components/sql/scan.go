@@ -0,0 +1,128 @@
+package sql
+
+import (
+	stdsql "database/sql"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	scannerType = reflect.TypeOf((*stdsql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// isRowStruct reports whether 't' is a struct which is scanned field by field,
+// as opposed to structs which are single column values, such as time.Time and
+// sql.NullString (or anything else implementing sql.Scanner).
+func isRowStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+
+	return !reflect.PointerTo(t).Implements(scannerType)
+}
+
+// fieldAlloc is like reflect.Value.FieldByIndex, except that nil pointers to
+// embedded structs are allocated instead of causing a panic. It returns false
+// if such a pointer can not be allocated, i.e if its type is unexported.
+func fieldAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() && !v.CanSet() {
+				return v, false
+			}
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// dbField is a struct field mapped to a column.
+type dbField struct {
+	name  string
+	index []int
+}
+
+// dbFields returns the exported fields of struct type 't' along with their
+// column names, which is either the `db:"col"` tag or the field name. Fields
+// tagged `db:"-"` are omitted.
+func dbFields(t reflect.Type) []dbField {
+	fields := make([]dbField, 0, t.NumField())
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+
+		name := f.Tag.Get("db")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, dbField{name: name, index: f.Index})
+	}
+
+	return fields
+}
+
+// scanner scans rows into values of T. If T is a struct, then columns are
+// matched with fields by their `db:"col"` tag, or by name (case-insensitive)
+// if the tag is not set. Fields tagged `db:"-"` and columns without a field
+// are ignored, as are fields behind nil pointers to unexported embedded structs.
+// If T is not a struct (or is a single column value, see isRowStruct), then
+// rows must have a single column.
+type scanner[T any] struct {
+	isStruct bool
+	fields   [][]int // Field index per column, nil means ignored.
+}
+
+func newScanner[T any](cols []string) scanner[T] {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if !isRowStruct(t) {
+		return scanner[T]{}
+	}
+
+	byName := make(map[string][]int)
+	for _, f := range dbFields(t) {
+		byName[strings.ToLower(f.name)] = f.index
+	}
+
+	fields := make([][]int, len(cols))
+	for i, col := range cols {
+		fields[i] = byName[strings.ToLower(col)]
+	}
+
+	return scanner[T]{isStruct: true, fields: fields}
+}
+
+func (s scanner[T]) scan(rows *stdsql.Rows) (v T, err error) {
+	if !s.isStruct {
+		err = rows.Scan(&v)
+		return
+	}
+
+	rv := reflect.ValueOf(&v).Elem()
+	dst := make([]any, len(s.fields))
+	for i, index := range s.fields {
+		f, ok := fieldAlloc(rv, index)
+		if index == nil || !ok {
+			dst[i] = new(any)
+			continue
+		}
+
+		dst[i] = f.Addr().Interface()
+	}
+
+	err = rows.Scan(dst...)
+	return
+}
@@ -0,0 +1,229 @@
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"io"
+
+	"github.com/crunchypi/gtl/components/page"
+	"github.com/crunchypi/gtl/core"
+)
+
+// Queryer runs queries, it is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*stdsql.Rows, error)
+}
+
+// NewRowsReader returns a ReadCloser which scans 'rows' into values of T. If T
+// is a struct, then columns are matched with fields by their `db:"col"` tag,
+// or by name (case-insensitive) if there is no tag. Fields tagged `db:"-"` and
+// columns without a matching field are ignored. If T is not a struct, then the
+// rows must have a single column, which is scanned into T.
+//
+// 'rows' is closed when they are exhausted (io.EOF is returned), on errs (which
+// are returned as-is), when the ctx given to Read is done, and on Close. Nil
+// 'rows' returns an empty non-nil ReadCloser.
+//
+// Example:
+//
+//	type user struct {
+//	    ID   int    `db:"id"`
+//	    Name string `db:"name"`
+//	}
+//
+//	rows, _ := db.QueryContext(ctx, "SELECT id, name FROM users")
+//	r := NewRowsReader[user](rows)
+//	defer r.Close()
+func NewRowsReader[T any](rows *stdsql.Rows) core.ReadCloser[T] {
+	if rows == nil {
+		return core.ReadCloserImpl[T]{}
+	}
+
+	var s *scanner[T]
+	closed := false
+	closeRows := func() error {
+		if closed {
+			return nil
+		}
+
+		closed = true
+		return rows.Close()
+	}
+
+	return core.ReadCloserImpl[T]{
+		ImplC: closeRows,
+		ImplR: func(ctx context.Context) (val T, err error) {
+			if closed {
+				return val, io.EOF
+			}
+			if ctx != nil && ctx.Err() != nil {
+				closeRows()
+				return val, ctx.Err()
+			}
+			if s == nil {
+				cols, err := rows.Columns()
+				if err != nil {
+					closeRows()
+					return val, err
+				}
+
+				_s := newScanner[T](cols)
+				s = &_s
+			}
+
+			if !rows.Next() {
+				err = rows.Err()
+				closeRows()
+				if err == nil {
+					err = io.EOF
+				}
+
+				return val, err
+			}
+
+			val, err = s.scan(rows)
+			if err != nil {
+				closeRows()
+			}
+
+			return val, err
+		},
+	}
+}
+
+// queryAll runs a query and scans all rows, see NewRowsReader.
+func queryAll[T any](ctx context.Context, db Queryer, query string, args []any) ([]T, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewRowsReader[T](rows)
+	defer r.Close()
+
+	vals := make([]T, 0, 8)
+	for {
+		v, err := r.Read(ctx)
+		if err == io.EOF {
+			return vals, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		vals = append(vals, v)
+	}
+}
+
+type NewQueryReaderArgs[T any] struct {
+	// DB runs queries. On nil, the func returns core.ReaderImpl[T].
+	DB Queryer
+	// Pages are the pages which are queried, e.g page.NewOnceReader. On nil,
+	// the func returns core.ReaderImpl[T].
+	Pages core.Reader[page.Page]
+	// Query returns the query and its args for a page. On nil, the func
+	// returns core.ReaderImpl[T].
+	Query func(p page.Page) (query string, args []any)
+	// Workers is the max number of concurrent queries, see
+	// page.NewFetchReaderArgs.
+	Workers int
+}
+
+// NewQueryReader returns a Reader which runs a query per page from args.Pages
+// (see page.NewFetchReader) and scans the rows into values of T, see
+// NewRowsReader for how rows are scanned. Values are returned in page order,
+// io.EOF is returned when args.Pages is exhausted or a page is empty.
+//
+// Example:
+//
+//	r := NewQueryReader(
+//	    NewQueryReaderArgs[user]{
+//	        DB:    db,
+//	        Pages: page.NewOnceReader(page.NewOnceReaderArgs{Total: n, Limit: 500}),
+//	        Query: func(p page.Page) (string, []any) {
+//	            return "SELECT id, name FROM users ORDER BY id LIMIT ? OFFSET ?",
+//	                []any{p.Limit, p.Skip}
+//	        },
+//	    },
+//	)
+func NewQueryReader[T any](args NewQueryReaderArgs[T]) core.Reader[T] {
+	if args.DB == nil || args.Pages == nil || args.Query == nil {
+		return core.ReaderImpl[T]{}
+	}
+
+	r := page.NewFetchReader(
+		page.NewFetchReaderArgs[T]{
+			Reader: args.Pages,
+			Fetch: func(ctx context.Context, p page.Page) ([]T, error) {
+				query, qargs := args.Query(p)
+				return queryAll[T](ctx, args.DB, query, qargs)
+			},
+			Workers: args.Workers,
+		},
+	)
+
+	return core.NewReaderWithUnbatching[T](
+		core.ReaderImpl[[]T]{
+			Impl: func(ctx context.Context) ([]T, error) {
+				p, err := r.Read(ctx)
+				return p.Val, err
+			},
+		},
+	)
+}
+
+type NewKeysetReaderArgs[T any, K comparable] struct {
+	// DB runs queries. On nil, the func returns core.ReaderImpl[T].
+	DB Queryer
+	// Query returns the query and its args for the page after key 'after'.
+	// On nil, the func returns core.ReaderImpl[T].
+	Query func(after K, limit int) (query string, args []any)
+	// Key returns the key of a value. On nil, the func returns
+	// core.ReaderImpl[T].
+	Key func(T) K
+	// Start is the key given to Query for the first page.
+	Start K
+	// Limit is given to Query. On <= 0, defaults to 100.
+	Limit int
+}
+
+// NewKeysetReader returns a Reader which pages through a table with keyset
+// pagination (see page.NewKeysetReader), e.g "WHERE id > ? ORDER BY id LIMIT ?",
+// and scans the rows into values of T, see NewRowsReader for how rows are
+// scanned.
+//
+// Example:
+//
+//	r := NewKeysetReader(
+//	    NewKeysetReaderArgs[user, int]{
+//	        DB: db,
+//	        Query: func(after int, limit int) (string, []any) {
+//	            return "SELECT id, name FROM users WHERE id > ? ORDER BY id LIMIT ?",
+//	                []any{after, limit}
+//	        },
+//	        Key: func(u user) int { return u.ID },
+//	    },
+//	)
+func NewKeysetReader[T any, K comparable](args NewKeysetReaderArgs[T, K]) core.Reader[T] {
+	if args.DB == nil || args.Query == nil || args.Key == nil {
+		return core.ReaderImpl[T]{}
+	}
+
+	return core.NewReaderWithUnbatching(
+		page.NewKeysetReader(
+			page.NewKeysetReaderArgs[T, K]{
+				Fetch: func(ctx context.Context, after K, limit int) ([]T, error) {
+					query, qargs := args.Query(after, limit)
+					return queryAll[T](ctx, args.DB, query, qargs)
+				},
+				Key:   args.Key,
+				Start: args.Start,
+				Limit: args.Limit,
+			},
+		),
+	)
+}
@@ -0,0 +1,271 @@
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/crunchypi/gtl/components/page"
+	"github.com/crunchypi/gtl/core"
+)
+
+func assertEq[T any](subject string, want T, have T, f func(string)) {
+	if f == nil {
+		return
+	}
+
+	ab, _ := json.Marshal(want)
+	bb, _ := json.Marshal(have)
+
+	as := string(ab)
+	bs := string(bb)
+
+	if as == bs {
+		return
+	}
+
+	s := "unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n"
+	f(fmt.Sprintf(s, subject, as, bs))
+}
+
+func tfReadAll[T any](ctx context.Context, r core.Reader[T]) ([]T, error) {
+	var v T
+	var s = make([]T, 0, 8)
+	var err error
+
+	for v, err = r.Read(ctx); err == nil; v, err = r.Read(ctx) {
+		s = append(s, v)
+	}
+
+	return s, err
+}
+
+type tvUser struct {
+	ID      int64  `db:"id"`
+	Name    string `db:"user_name"`
+	Email   string
+	Ignored string `db:"-"`
+}
+
+// tvUsers is a table of users with ids 1-5.
+var tvUsers = func() [][]driver.Value {
+	rows := make([][]driver.Value, 0, 5)
+	for i := int64(1); i <= 5; i++ {
+		rows = append(rows, []driver.Value{i, fmt.Sprint("u", i), "x", "extra"})
+	}
+
+	return rows
+}()
+
+var tvUserCols = []string{"id", "user_name", "EMAIL", "extra"}
+
+// -----------------------------------------------------------------------------
+// Tests: NewRowsReader.
+// -----------------------------------------------------------------------------
+
+func TestNewRowsReaderIdeal(t *testing.T) {
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			return tvUserCols, tvUsers[:2], nil
+		},
+	}
+
+	rows, err := tfNewDB(db).Query("SELECT")
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	vals, err := tfReadAll(context.Background(), NewRowsReader[tvUser](rows))
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []tvUser{{1, "u1", "x", ""}, {2, "u2", "x", ""}}, vals, func(s string) { t.Fatal(s) })
+	assertEq("openRows", 0, db.openRows, func(s string) { t.Fatal(s) })
+}
+
+func TestNewRowsReaderWithNonStruct(t *testing.T) {
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			return []string{"n"}, [][]driver.Value{{int64(1)}, {int64(2)}}, nil
+		},
+	}
+
+	rows, _ := tfNewDB(db).Query("SELECT")
+	vals, err := tfReadAll(context.Background(), NewRowsReader[int](rows))
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewRowsReaderWithScanner(t *testing.T) {
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			return []string{"name"}, [][]driver.Value{{"alice"}, {nil}}, nil
+		},
+	}
+
+	rows, _ := tfNewDB(db).Query("SELECT")
+	vals, err := tfReadAll(context.Background(), NewRowsReader[stdsql.NullString](rows))
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []stdsql.NullString{{String: "alice", Valid: true}, {}}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewRowsReaderWithTime(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			return []string{"ts"}, [][]driver.Value{{ts}}, nil
+		},
+	}
+
+	rows, _ := tfNewDB(db).Query("SELECT")
+	vals, err := tfReadAll(context.Background(), NewRowsReader[time.Time](rows))
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []time.Time{ts}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewRowsReaderWithEmbeddedPointer(t *testing.T) {
+	type Meta struct {
+		Email string
+	}
+	type meta struct {
+		Phone string
+	}
+	type user struct {
+		ID int64 `db:"id"`
+		*Meta
+		*meta // Can not be allocated, so it is skipped.
+	}
+
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			return []string{"id", "email", "phone"}, [][]driver.Value{{int64(1), "x", "y"}}, nil
+		},
+	}
+
+	rows, _ := tfNewDB(db).Query("SELECT")
+	vals, err := tfReadAll(context.Background(), NewRowsReader[user](rows))
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("len", 1, len(vals), func(s string) { t.Fatal(s) })
+	assertEq("email", "x", vals[0].Email, func(s string) { t.Fatal(s) })
+	assertEq("meta", true, vals[0].meta == nil, func(s string) { t.Fatal(s) })
+}
+
+func TestNewRowsReaderWithCancel(t *testing.T) {
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			return tvUserCols, tvUsers, nil
+		},
+	}
+
+	rows, _ := tfNewDB(db).Query("SELECT")
+	r := NewRowsReader[tvUser](rows)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.Read(ctx)
+	cancel()
+
+	_, err := r.Read(ctx)
+	assertEq("err", context.Canceled, err, func(s string) { t.Fatal(s) })
+	assertEq("openRows", 0, db.openRows, func(s string) { t.Fatal(s) })
+
+	_, err = r.Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewRowsReaderWithClose(t *testing.T) {
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			return tvUserCols, tvUsers, nil
+		},
+	}
+
+	rows, _ := tfNewDB(db).Query("SELECT")
+	r := NewRowsReader[tvUser](rows)
+	r.Read(context.Background())
+
+	assertEq("err", *new(error), r.Close(), func(s string) { t.Fatal(s) })
+	assertEq("openRows", 0, db.openRows, func(s string) { t.Fatal(s) })
+}
+
+func TestNewRowsReaderWithNilRows(t *testing.T) {
+	_, err := NewRowsReader[int](nil).Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewQueryReader.
+// -----------------------------------------------------------------------------
+
+func TestNewQueryReaderIdeal(t *testing.T) {
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			limit, offset := int(args[0].(int64)), int(args[1].(int64))
+			return tvUserCols, tvUsers[offset : offset+limit], nil
+		},
+	}
+
+	r := NewQueryReader(
+		NewQueryReaderArgs[tvUser]{
+			DB:    tfNewDB(db),
+			Pages: page.NewOnceReader(page.NewOnceReaderArgs{Total: 5, Limit: 2}),
+			Query: func(p page.Page) (string, []any) {
+				return "SELECT * FROM users LIMIT ? OFFSET ?", []any{p.Limit, p.Skip}
+			},
+			Workers: 2,
+		},
+	)
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("len", 5, len(vals), func(s string) { t.Fatal(s) })
+	for i, v := range vals {
+		assertEq("id", int64(i+1), v.ID, func(s string) { t.Fatal(s) })
+	}
+
+	assertEq("queries", 3, len(db.queries), func(s string) { t.Fatal(s) })
+	assertEq("openRows", 0, db.openRows, func(s string) { t.Fatal(s) })
+}
+
+func TestNewQueryReaderWithNilDB(t *testing.T) {
+	_, err := NewQueryReader(NewQueryReaderArgs[tvUser]{}).Read(context.Background())
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+// -----------------------------------------------------------------------------
+// Tests: NewKeysetReader.
+// -----------------------------------------------------------------------------
+
+func TestNewKeysetReaderIdeal(t *testing.T) {
+	db := &tfDB{
+		query: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+			after, limit := args[0].(int64), int(args[1].(int64))
+
+			rows := make([][]driver.Value, 0, limit)
+			for _, row := range tvUsers {
+				if row[0].(int64) > after && len(rows) < limit {
+					rows = append(rows, row)
+				}
+			}
+
+			return tvUserCols, rows, nil
+		},
+	}
+
+	r := NewKeysetReader(
+		NewKeysetReaderArgs[tvUser, int64]{
+			DB: tfNewDB(db),
+			Query: func(after int64, limit int) (string, []any) {
+				return "SELECT * FROM users WHERE id > ? ORDER BY id LIMIT ?", []any{after, limit}
+			},
+			Key:   func(u tvUser) int64 { return u.ID },
+			Limit: 2,
+		},
+	)
+
+	vals, err := tfReadAll(context.Background(), r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("len", 5, len(vals), func(s string) { t.Fatal(s) })
+	assertEq[any]("after", int64(4), db.queries[2].args[0], func(s string) { t.Fatal(s) })
+	assertEq("openRows", 0, db.openRows, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+var liveVarsMx sync.Mutex
+
+// liveVars are live counters published with expvar, see the "Expvar" field
+// of the tee args in this pkg. A nil *liveVars is valid and does nothing.
+type liveVars struct {
+	m  *expvar.Map
+	d  *expvar.Float
+	op string // "reads" or "writes".
+}
+
+// newLiveVars returns liveVars which are published under 'tag' in the "gtl"
+// expvar map, the vars are shared if the tag is used several times. Using a
+// single map keeps tags from clashing with other expvars of the application.
+// If 'enabled' is false, or if "gtl" is already used by an expvar which is not
+// an *expvar.Map, then nil is returned.
+func newLiveVars(enabled bool, tag string, op string) *liveVars {
+	if !enabled {
+		return nil
+	}
+
+	liveVarsMx.Lock()
+	defer liveVarsMx.Unlock()
+
+	root, ok := expvar.Get("gtl").(*expvar.Map)
+	if !ok && expvar.Get("gtl") != nil {
+		return nil
+	}
+	if !ok {
+		root = expvar.NewMap("gtl")
+	}
+
+	m, ok := root.Get(tag).(*expvar.Map)
+	if !ok {
+		m = new(expvar.Map)
+		m.Add("reads", 0)
+		m.Add("writes", 0)
+		m.Add("errors", 0)
+		m.Add("inFlight", 0)
+		m.AddFloat("lastDeltaSeconds", 0)
+		root.Set(tag, m)
+	}
+
+	d, ok := m.Get("lastDeltaSeconds").(*expvar.Float)
+	if !ok {
+		return nil
+	}
+
+	return &liveVars{m: m, d: d, op: op}
+}
+
+// begin is called before a read/write.
+func (v *liveVars) begin() {
+	if v == nil {
+		return
+	}
+
+	v.m.Add("inFlight", 1)
+}
+
+// end is called after a read/write.
+func (v *liveVars) end() {
+	if v == nil {
+		return
+	}
+
+	v.m.Add("inFlight", -1)
+}
+
+// record is called when stats are made.
+func (v *liveVars) record(err error, d time.Duration) {
+	if v == nil {
+		return
+	}
+
+	v.m.Add(v.op, 1)
+	if err != nil {
+		v.m.Add("errors", 1)
+	}
+
+	v.d.Set(d.Seconds())
+}
@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+var tvExpvarN atomic.Int64
+
+// tfExpvarTag returns a tag which is unique for this process, since expvars
+// are global and tests may run several times (-count).
+func tfExpvarTag(t *testing.T) string {
+	return fmt.Sprintf("%s-%d", t.Name(), tvExpvarN.Add(1))
+}
+
+func tfExpvarMap(t *testing.T, tag string) map[string]float64 {
+	root, ok := expvar.Get("gtl").(*expvar.Map)
+	if !ok || root.Get(tag) == nil {
+		t.Fatalf("expvar '%v' not published", tag)
+	}
+
+	m := make(map[string]float64)
+	json.Unmarshal([]byte(root.Get(tag).String()), &m)
+	return m
+}
+
+func TestExpvarIdeal(t *testing.T) {
+	tag := tfExpvarTag(t)
+
+	r := NewStreamedTeeReader(
+		NewStreamedTeeReaderArgs[int, int]{
+			Reader: core.NewReaderFrom(1, 2, 3),
+			Writer: core.WriterImpl[StatsStreamed[int]]{
+				Impl: func(ctx context.Context, s StatsStreamed[int]) error { return nil },
+			},
+			Tag:    tag,
+			Expvar: true,
+		},
+	)
+
+	w := NewBatchedTeeWriter(
+		NewBatchedTeeWriterArgs[int]{
+			WriterVals: core.WriterImpl[[]int]{
+				Impl: func(ctx context.Context, s []int) error { return tvErr },
+			},
+			Tag:    tag,
+			Expvar: true,
+		},
+	)
+
+	before := tfExpvarMap(t, tag)
+	for _, err := r.Read(tvCtx); err == nil; _, err = r.Read(tvCtx) {
+	}
+
+	w.Write(tvCtx, []int{1})
+
+	m := tfExpvarMap(t, tag)
+	assertEq("reads", 3.0, m["reads"]-before["reads"], func(s string) { t.Fatal(s) })
+	assertEq("writes", 1.0, m["writes"]-before["writes"], func(s string) { t.Fatal(s) })
+	assertEq("errors", 1.0, m["errors"]-before["errors"], func(s string) { t.Fatal(s) })
+	assertEq("inFlight", 0.0, m["inFlight"]-before["inFlight"], func(s string) { t.Fatal(s) })
+}
+
+func TestExpvarWithInFlight(t *testing.T) {
+	tag := tfExpvarTag(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	r := NewBatchedTeeReader(
+		NewBatchedTeeReaderArgs[int]{
+			Reader: core.ReaderImpl[[]int]{
+				Impl: func(ctx context.Context) ([]int, error) {
+					started <- struct{}{}
+					<-release
+					return []int{1}, nil
+				},
+			},
+			Tag:    tag,
+			Expvar: true,
+		},
+	)
+
+	before := tfExpvarMap(t, tag)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.Read(tvCtx)
+	}()
+
+	<-started
+	m := tfExpvarMap(t, tag)
+	assertEq("inFlight", 1.0, m["inFlight"]-before["inFlight"], func(s string) { t.Fatal(s) })
+
+	close(release)
+	wg.Wait()
+	m = tfExpvarMap(t, tag)
+	assertEq("inFlight", 0.0, m["inFlight"]-before["inFlight"], func(s string) { t.Fatal(s) })
+	assertEq("reads", 1.0, m["reads"]-before["reads"], func(s string) { t.Fatal(s) })
+}
+
+func TestExpvarWithTagOfOtherExpvar(t *testing.T) {
+	tag := tfExpvarTag(t)
+	expvar.NewString(tag)
+
+	// Tags live in the "gtl" map, so they do not clash with other expvars.
+	r := NewStreamedTeeReader(
+		NewStreamedTeeReaderArgs[int, int]{
+			Reader: core.NewReaderFrom(1),
+			Tag:    tag,
+			Expvar: true,
+		},
+	)
+
+	r.Read(tvCtx)
+	assertEq("reads", 1.0, tfExpvarMap(t, tag)["reads"], func(s string) { t.Fatal(s) })
+}
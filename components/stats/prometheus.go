@@ -0,0 +1,309 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// DefaultPromBuckets are the default histogram buckets (in seconds) used by
+// Prom, they are the same as the defaults of the official Prometheus client.
+var DefaultPromBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type NewPromArgs struct {
+	// Namespace is prefixed to all metric names. On "", defaults to "gtl".
+	Namespace string
+	// CtxKeys selects keys from StatsStreamed.CtxMap and StatsBatched.CtxMap
+	// which are used as labels, in addition to "tag". Values are formatted
+	// with fmt.Sprint, nil values become "". Label names are the keys with
+	// invalid chars replaced by '_', prefixed with "ctx_" if that gives a
+	// name which is empty, reserved ("__" prefix) or already used, either by
+	// Prom ("tag", "kind" and "le") or a previous key. Keys for which the
+	// prefixed name is used as well are ignored.
+	CtxKeys []string
+	// Buckets are upper bounds (in seconds) for the histogram of Delta. On nil,
+	// defaults to DefaultPromBuckets.
+	Buckets []float64
+}
+
+// Prom aggregates stats into metrics which are exposed in the Prometheus text
+// format, it implements http.Handler. Use NewPromStreamedWriter and
+// NewPromBatchedWriter to feed it, e.g as the Writer of NewStreamedTeeReader.
+// Prom is safe for concurrent use.
+//
+// Exposed metrics, all labelled by "tag" and args.CtxKeys:
+//   - <ns>_values_total: Counter of values, i.e 1 per StatsStreamed or
+//     StatsBatched.Len per StatsBatched.
+//   - <ns>_errors_total: Counter of errs, additionally labelled by "kind",
+//     which is the ErrClass of the stats (Classify is used if it is unset).
+//   - <ns>_delta_last_seconds: Gauge of the last Delta.
+//   - <ns>_delta_seconds: Histogram of Delta.
+type Prom struct {
+	mx      sync.Mutex
+	ns      string
+	keys    []string
+	names   []string // Label names of keys.
+	buckets []float64
+	series  map[string]*promSeries
+}
+
+type promSeries struct {
+	labels    []string // Label vals, matching "tag" + Prom.keys.
+	values    float64
+	errors    map[string]float64
+	deltaLast float64
+	deltaSum  float64
+	deltaN    uint64
+	deltaBkts []uint64 // Non-cumulative, last is +Inf.
+}
+
+// NewProm returns a new Prom, see NewPromArgs and Prom for details.
+func NewProm(args NewPromArgs) *Prom {
+	if args.Namespace == "" {
+		args.Namespace = "gtl"
+	}
+	if args.Buckets == nil {
+		args.Buckets = DefaultPromBuckets
+	}
+
+	buckets := append([]float64(nil), args.Buckets...)
+	sort.Float64s(buckets)
+
+	keys := make([]string, 0, len(args.CtxKeys))
+	names := make([]string, 0, len(args.CtxKeys))
+	used := map[string]bool{"tag": true, "kind": true, "le": true}
+	for _, k := range args.CtxKeys {
+		name := promName(k)
+		if name == "" || strings.HasPrefix(name, "__") || used[name] {
+			name = "ctx_" + name
+		}
+		if used[name] {
+			continue
+		}
+
+		used[name] = true
+		keys = append(keys, k)
+		names = append(names, name)
+	}
+
+	return &Prom{
+		ns:      args.Namespace,
+		keys:    keys,
+		names:   names,
+		buckets: buckets,
+		series:  make(map[string]*promSeries),
+	}
+}
+
+// NewPromStreamedWriter returns a Writer which feeds StatsStreamed into 'p'.
+// Nil 'p' returns an empty non-nil Writer.
+//
+// Example:
+//
+//	p := NewProm(NewPromArgs{})
+//	http.Handle("/metrics", p)
+//
+//	r := NewStreamedTeeReader(
+//	    NewStreamedTeeReaderArgs[int, int]{
+//	        Reader: myReader,
+//	        Writer: NewPromStreamedWriter[int](p),
+//	        Tag:    "myReader",
+//	    },
+//	)
+func NewPromStreamedWriter[U any](p *Prom) core.Writer[StatsStreamed[U]] {
+	if p == nil {
+		return core.WriterImpl[StatsStreamed[U]]{}
+	}
+
+	return core.WriterImpl[StatsStreamed[U]]{
+		Impl: func(ctx context.Context, s StatsStreamed[U]) error {
+			p.add(s.Tag, s.CtxMap, 1, s.Err, s.ErrClass, s.Delta)
+			return nil
+		},
+	}
+}
+
+// NewPromBatchedWriter returns a Writer which feeds StatsBatched into 'p'.
+// Nil 'p' returns an empty non-nil Writer.
+func NewPromBatchedWriter(p *Prom) core.Writer[StatsBatched] {
+	if p == nil {
+		return core.WriterImpl[StatsBatched]{}
+	}
+
+	return core.WriterImpl[StatsBatched]{
+		Impl: func(ctx context.Context, s StatsBatched) error {
+			p.add(s.Tag, s.CtxMap, s.Len, s.Err, s.ErrClass, s.Delta)
+			return nil
+		},
+	}
+}
+
+func (p *Prom) add(tag string, m map[string]any, n int, err error, c ErrClass, d time.Duration) {
+	labels := make([]string, 0, len(p.keys)+1)
+	labels = append(labels, tag)
+	for _, k := range p.keys {
+		v := ""
+		if x, ok := m[k]; ok && x != nil {
+			v = fmt.Sprint(x)
+		}
+
+		labels = append(labels, v)
+	}
+
+	id := strings.Join(labels, "\xff")
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	s, ok := p.series[id]
+	if !ok {
+		s = &promSeries{
+			labels:    labels,
+			errors:    make(map[string]float64),
+			deltaBkts: make([]uint64, len(p.buckets)+1),
+		}
+
+		p.series[id] = s
+	}
+
+	s.values += float64(n)
+	if c == ErrClassNone && err != nil {
+		c = Classify(err)
+	}
+	if c != ErrClassNone {
+		s.errors[string(c)]++
+	}
+
+	secs := d.Seconds()
+	s.deltaLast = secs
+	s.deltaSum += secs
+	s.deltaN++
+	s.deltaBkts[sort.SearchFloat64s(p.buckets, secs)]++
+}
+
+// ServeHTTP implements http.Handler by writing all metrics in the Prometheus
+// text exposition format.
+func (p *Prom) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+// WriteTo writes all metrics to 'w' in the Prometheus text exposition format.
+func (p *Prom) WriteTo(w io.Writer) (n int64, err error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	ids := make([]string, 0, len(p.series))
+	for id := range p.series {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	b := strings.Builder{}
+	header := func(name, typ, help string) {
+		fmt.Fprintf(&b, "# HELP %s_%s %s\n", p.ns, name, help)
+		fmt.Fprintf(&b, "# TYPE %s_%s %s\n", p.ns, name, typ)
+	}
+
+	header("values_total", "counter", "Number of values seen by stats tees.")
+	for _, id := range ids {
+		s := p.series[id]
+		fmt.Fprintf(&b, "%s_values_total%s %s\n", p.ns, p.labels(s), promFloat(s.values))
+	}
+
+	header("errors_total", "counter", "Number of errors seen by stats tees.")
+	for _, id := range ids {
+		s := p.series[id]
+
+		kinds := make([]string, 0, len(s.errors))
+		for k := range s.errors {
+			kinds = append(kinds, k)
+		}
+
+		sort.Strings(kinds)
+		for _, k := range kinds {
+			l := p.labels(s, "kind", k)
+			fmt.Fprintf(&b, "%s_errors_total%s %s\n", p.ns, l, promFloat(s.errors[k]))
+		}
+	}
+
+	header("delta_last_seconds", "gauge", "Last delta between stats records.")
+	for _, id := range ids {
+		s := p.series[id]
+		fmt.Fprintf(&b, "%s_delta_last_seconds%s %s\n", p.ns, p.labels(s), promFloat(s.deltaLast))
+	}
+
+	header("delta_seconds", "histogram", "Delta between stats records.")
+	for _, id := range ids {
+		s := p.series[id]
+
+		cum := uint64(0)
+		for i, bound := range p.buckets {
+			cum += s.deltaBkts[i]
+			l := p.labels(s, "le", promFloat(bound))
+			fmt.Fprintf(&b, "%s_delta_seconds_bucket%s %d\n", p.ns, l, cum)
+		}
+
+		l := p.labels(s, "le", "+Inf")
+		fmt.Fprintf(&b, "%s_delta_seconds_bucket%s %d\n", p.ns, l, s.deltaN)
+		fmt.Fprintf(&b, "%s_delta_seconds_sum%s %s\n", p.ns, p.labels(s), promFloat(s.deltaSum))
+		fmt.Fprintf(&b, "%s_delta_seconds_count%s %d\n", p.ns, p.labels(s), s.deltaN)
+	}
+
+	m, err := io.WriteString(w, b.String())
+	return int64(m), err
+}
+
+// labels formats the labels of 's', with optional extra k:v pairs.
+func (p *Prom) labels(s *promSeries, extra ...string) string {
+	b := strings.Builder{}
+	b.WriteString(`{tag="`)
+	b.WriteString(promEscape(s.labels[0]))
+	b.WriteString(`"`)
+
+	for i, name := range p.names {
+		fmt.Fprintf(&b, `,%s="%s"`, name, promEscape(s.labels[i+1]))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		fmt.Fprintf(&b, `,%s="%s"`, extra[i], promEscape(extra[i+1]))
+	}
+
+	b.WriteString("}")
+	return b.String()
+}
+
+func promFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func promEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return s
+}
+
+// promName makes 's' a valid label name by replacing invalid chars with '_'.
+func promName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		ok := c == '_'
+		ok = ok || (c >= 'a' && c <= 'z')
+		ok = ok || (c >= 'A' && c <= 'Z')
+		ok = ok || (c >= '0' && c <= '9' && i > 0)
+		if !ok {
+			b[i] = '_'
+		}
+	}
+
+	return string(b)
+}
@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func tfScrape(t *testing.T, p *Prom) string {
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	ct := rec.Header().Get("Content-Type")
+	assertEq("content-type", "text/plain; version=0.0.4; charset=utf-8", ct, func(s string) { t.Fatal(s) })
+
+	b, _ := io.ReadAll(rec.Body)
+	return string(b)
+}
+
+func tfAssertContains(t *testing.T, body string, lines ...string) {
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("missing line '%v' in:\n%v", line, body)
+		}
+	}
+}
+
+func TestPromIdeal(t *testing.T) {
+	p := NewProm(NewPromArgs{CtxKeys: []string{tvCtxKey}, Buckets: []float64{1, 2}})
+
+	r := NewStreamedTeeReader(
+		NewStreamedTeeReaderArgs[int, int]{
+			Reader:  core.NewReaderFrom(1, 2, 3),
+			Writer:  NewPromStreamedWriter[int](p),
+			Tag:     "test",
+			CtxKeys: []string{tvCtxKey},
+		},
+	)
+
+	for _, err := r.Read(tvCtx); err == nil; _, err = r.Read(tvCtx) {
+	}
+
+	tfAssertContains(
+		t,
+		tfScrape(t, p),
+		"# TYPE gtl_values_total counter",
+		`gtl_values_total{tag="test",testKey="testVal"} 3`,
+		"# TYPE gtl_delta_seconds histogram",
+		`gtl_delta_seconds_bucket{tag="test",testKey="testVal",le="1"} 3`,
+		`gtl_delta_seconds_bucket{tag="test",testKey="testVal",le="+Inf"} 3`,
+		`gtl_delta_seconds_count{tag="test",testKey="testVal"} 3`,
+	)
+}
+
+func TestPromWithBatchedErrs(t *testing.T) {
+	p := NewProm(NewPromArgs{Namespace: "test"})
+	w := NewPromBatchedWriter(p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w.Write(nil, StatsBatched{Tag: "a", Len: 2, Delta: time.Second * 3})
+	w.Write(nil, StatsBatched{Tag: "a", Len: 1, Err: tvErr})
+	w.Write(nil, StatsBatched{Tag: "a", Err: ctx.Err()})
+	w.Write(nil, StatsBatched{Tag: "b", Err: io.ErrClosedPipe})
+
+	tfAssertContains(
+		t,
+		tfScrape(t, p),
+		`test_values_total{tag="a"} 3`,
+		`test_values_total{tag="b"} 0`,
+		`test_errors_total{tag="a",kind="canceled"} 1`,
+		`test_errors_total{tag="a",kind="other"} 1`,
+		`test_errors_total{tag="b",kind="closed_pipe"} 1`,
+		`test_delta_last_seconds{tag="a"} 0`,
+		`test_delta_seconds_bucket{tag="a",le="2.5"} 2`,
+		`test_delta_seconds_bucket{tag="a",le="5"} 3`,
+		`test_delta_seconds_sum{tag="a"} 3`,
+	)
+}
+
+func TestPromWithNilProm(t *testing.T) {
+	err := NewPromBatchedWriter(nil).Write(nil, StatsBatched{})
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+
+	err = NewPromStreamedWriter[int](nil).Write(nil, StatsStreamed[int]{})
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestPromWithEscapedLabels(t *testing.T) {
+	p := NewProm(NewPromArgs{CtxKeys: []string{"my-key"}})
+	w := NewPromBatchedWriter(p)
+
+	w.Write(nil, StatsBatched{Tag: `a"b`, Len: 1, CtxMap: map[string]any{"my-key": 1}})
+
+	tfAssertContains(t, tfScrape(t, p), `gtl_values_total{tag="a\"b",my_key="1"} 1`)
+}
+
+func TestPromWithReservedLabels(t *testing.T) {
+	p := NewProm(NewPromArgs{CtxKeys: []string{"tag", "le", "", "__x", "a-b", "a_b", "tag"}})
+	w := NewPromBatchedWriter(p)
+
+	m := map[string]any{"tag": 1, "le": 2, "": 3, "__x": 4, "a-b": 5, "a_b": 6}
+	w.Write(nil, StatsBatched{Tag: "t", Len: 1, CtxMap: m})
+
+	want := `gtl_values_total{tag="t",ctx_tag="1",ctx_le="2",ctx_="3",ctx___x="4",a_b="5",ctx_a_b="6"} 1`
+	tfAssertContains(t, tfScrape(t, p), want)
+}
@@ -9,22 +9,73 @@ import (
 	"github.com/crunchypi/gtl/core"
 )
 
+// ErrClass is a coarse classification of errs, see Classify.
+type ErrClass string
+
+const (
+	ErrClassNone       ErrClass = ""
+	ErrClassEOF        ErrClass = "eof"
+	ErrClassClosedPipe ErrClass = "closed_pipe"
+	ErrClassCanceled   ErrClass = "canceled"
+	ErrClassDeadline   ErrClass = "deadline"
+	ErrClassOther      ErrClass = "other"
+)
+
+// Classify is the default err classifier used in this pkg. It checks errs with
+// errors.Is against io.EOF, io.ErrClosedPipe, context.Canceled and
+// context.DeadlineExceeded (in that order), anything else is ErrClassOther.
+// A nil err gives ErrClassNone.
+func Classify(err error) ErrClass {
+	switch {
+	case err == nil:
+		return ErrClassNone
+	case errors.Is(err, io.EOF):
+		return ErrClassEOF
+	case errors.Is(err, io.ErrClosedPipe):
+		return ErrClassClosedPipe
+	case errors.Is(err, context.Canceled):
+		return ErrClassCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrClassDeadline
+	default:
+		return ErrClassOther
+	}
+}
+
+// StatsStreamed contains stats for a single value, see NewStreamedTeeReader.
+// Note that Err is not serialized since most errs encode as "{}", ErrMsg
+// (i.e err.Error()) and ErrClass are serialized instead.
 type StatsStreamed[T any] struct {
-	Tag    string         `json:"tag"`
-	Val    T              `json:"val"`
-	Err    error          `json:"err"`
-	CtxMap map[string]any `json:"ctx"`
-	Stamp  time.Time      `json:"stamp"`
-	Delta  time.Duration  `json:"delta"`
+	Tag      string         `json:"tag"`
+	Val      T              `json:"val"`
+	Err      error          `json:"-"`
+	ErrMsg   string         `json:"err"`
+	ErrClass ErrClass       `json:"errClass"`
+	CtxMap   map[string]any `json:"ctx"`
+	Stamp    time.Time      `json:"stamp"`
+	Delta    time.Duration  `json:"delta"`
 }
 
+// StatsBatched contains stats for a batch of values, see NewBatchedTeeReader.
+// Note that Err is not serialized since most errs encode as "{}", ErrMsg
+// (i.e err.Error()) and ErrClass are serialized instead.
 type StatsBatched struct {
-	Tag    string         `json:"tag"`
-	Len    int            `json:"len"`
-	Err    error          `json:"err"`
-	CtxMap map[string]any `json:"ctx"`
-	Stamp  time.Time      `json:"stamp"`
-	Delta  time.Duration  `json:"delta"`
+	Tag      string         `json:"tag"`
+	Len      int            `json:"len"`
+	Err      error          `json:"-"`
+	ErrMsg   string         `json:"err"`
+	ErrClass ErrClass       `json:"errClass"`
+	CtxMap   map[string]any `json:"ctx"`
+	Stamp    time.Time      `json:"stamp"`
+	Delta    time.Duration  `json:"delta"`
+}
+
+func errMsg(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
 }
 
 type NewStreamedTeeReaderArgs[T, U any] struct {
@@ -45,6 +96,14 @@ type NewStreamedTeeReaderArgs[T, U any] struct {
 	// CtxKeys is used to extract values from the ctx given to the returned
 	// Reader. These k:v pairs are set to StatsStreamed.CtxMap.
 	CtxKeys []string
+	// Classify is used to set StatsStreamed.ErrClass. On nil, defaults to the
+	// Classify func of this pkg.
+	Classify func(error) ErrClass
+	// Expvar enables live counters which are published with expvar in the
+	// "gtl" map under Tag, i.e "reads", "writes", "errors", "inFlight" and
+	// "lastDeltaSeconds". The counters are shared by all tees with the same
+	// Tag, and are visible at e.g /debug/vars when using the expvar handler.
+	Expvar bool
 }
 
 // NewStreamedTeeReader returns a Reader[T] which pulls from args.Reader, while
@@ -67,14 +126,20 @@ func NewStreamedTeeReader[T, U any](args NewStreamedTeeReaderArgs[T, U]) core.Re
 	if args.Tag == "" {
 		args.Tag = "<unset>"
 	}
+	if args.Classify == nil {
+		args.Classify = Classify
+	}
 	if args.Fmt == nil {
 		args.Fmt = func(v T) (r U) { return }
 	}
 
+	vars := newLiveVars(args.Expvar, args.Tag, "reads")
 	stamp := time.Now()
 	return core.ReaderImpl[T]{
 		Impl: func(ctx context.Context) (val T, err error) {
+			vars.begin()
 			val, err = args.Reader.Read(ctx)
+			vars.end()
 			if err == io.EOF {
 				return
 			}
@@ -83,6 +148,8 @@ func NewStreamedTeeReader[T, U any](args NewStreamedTeeReaderArgs[T, U]) core.Re
 			stats.Tag = args.Tag
 			stats.Val = args.Fmt(val)
 			stats.Err = err
+			stats.ErrMsg = errMsg(err)
+			stats.ErrClass = args.Classify(err)
 			stats.CtxMap = make(map[string]any, len(args.CtxKeys))
 			stats.Stamp = time.Now()
 			stats.Delta = stats.Stamp.Sub(stamp)
@@ -94,6 +161,7 @@ func NewStreamedTeeReader[T, U any](args NewStreamedTeeReaderArgs[T, U]) core.Re
 			}
 
 			stamp = stats.Stamp
+			vars.record(stats.Err, stats.Delta)
 			err = errors.Join(err, args.Writer.Write(ctx, stats))
 			return
 		},
@@ -115,6 +183,14 @@ type NewBatchedTeeReaderArgs[T any] struct {
 	// CtxKeys is used to extract values from the ctx given to the returned
 	// Reader. These k:v pairs are set to StatsStreamed.CtxMap.
 	CtxKeys []string
+	// Classify is used to set StatsBatched.ErrClass. On nil, defaults to the
+	// Classify func of this pkg.
+	Classify func(error) ErrClass
+	// Expvar enables live counters which are published with expvar in the
+	// "gtl" map under Tag, i.e "reads", "writes", "errors", "inFlight" and
+	// "lastDeltaSeconds". The counters are shared by all tees with the same
+	// Tag, and are visible at e.g /debug/vars when using the expvar handler.
+	Expvar bool
 }
 
 // NewBatchedTeeReader returns a Reader[[]T] which pulls from args.Reader, while
@@ -139,11 +215,17 @@ func NewBatchedTeeReader[T any](args NewBatchedTeeReaderArgs[T]) core.Reader[[]T
 	if args.Tag == "" {
 		args.Tag = "<unset>"
 	}
+	if args.Classify == nil {
+		args.Classify = Classify
+	}
 
+	vars := newLiveVars(args.Expvar, args.Tag, "reads")
 	stamp := time.Now()
 	return core.ReaderImpl[[]T]{
 		Impl: func(ctx context.Context) (s []T, err error) {
+			vars.begin()
 			s, err = args.Reader.Read(ctx)
+			vars.end()
 			if err == io.EOF {
 				return
 			}
@@ -152,6 +234,8 @@ func NewBatchedTeeReader[T any](args NewBatchedTeeReaderArgs[T]) core.Reader[[]T
 			stats.Tag = args.Tag
 			stats.Len = len(s)
 			stats.Err = err
+			stats.ErrMsg = errMsg(err)
+			stats.ErrClass = args.Classify(err)
 			stats.CtxMap = make(map[string]any, len(args.CtxKeys))
 			stats.Stamp = time.Now()
 			stats.Delta = stats.Stamp.Sub(stamp)
@@ -163,6 +247,7 @@ func NewBatchedTeeReader[T any](args NewBatchedTeeReaderArgs[T]) core.Reader[[]T
 			}
 
 			stamp = stats.Stamp
+			vars.record(stats.Err, stats.Delta)
 			err = errors.Join(err, args.Writer.Write(ctx, stats))
 			return
 		},
@@ -188,6 +273,14 @@ type NewStreamedTeeWriterArgs[T, U any] struct {
 	// CtxKeys is used to extract values from the ctx given to the returned
 	// Reader. These k:v pairs are set to StatsStreamed.CtxMap.
 	CtxKeys []string
+	// Classify is used to set StatsStreamed.ErrClass. On nil, defaults to the
+	// Classify func of this pkg.
+	Classify func(error) ErrClass
+	// Expvar enables live counters which are published with expvar in the
+	// "gtl" map under Tag, i.e "reads", "writes", "errors", "inFlight" and
+	// "lastDeltaSeconds". The counters are shared by all tees with the same
+	// Tag, and are visible at e.g /debug/vars when using the expvar handler.
+	Expvar bool
 }
 
 // NewStreamedTeeWriter returns a Writer[T] which writes into args.WriterVals
@@ -210,14 +303,20 @@ func NewStreamedTeeWriter[T, U any](args NewStreamedTeeWriterArgs[T, U]) core.Wr
 	if args.Tag == "" {
 		args.Tag = "<unset>"
 	}
+	if args.Classify == nil {
+		args.Classify = Classify
+	}
 	if args.Fmt == nil {
 		args.Fmt = func(v T) (r U) { return }
 	}
 
+	vars := newLiveVars(args.Expvar, args.Tag, "writes")
 	stamp := time.Now()
 	return core.WriterImpl[T]{
 		Impl: func(ctx context.Context, val T) (err error) {
+			vars.begin()
 			err = args.WriterVals.Write(ctx, val)
+			vars.end()
 			if err == io.ErrClosedPipe {
 				return
 			}
@@ -226,6 +325,8 @@ func NewStreamedTeeWriter[T, U any](args NewStreamedTeeWriterArgs[T, U]) core.Wr
 			stats.Tag = args.Tag
 			stats.Val = args.Fmt(val)
 			stats.Err = err
+			stats.ErrMsg = errMsg(err)
+			stats.ErrClass = args.Classify(err)
 			stats.CtxMap = make(map[string]any, len(args.CtxKeys))
 			stats.Stamp = time.Now()
 			stats.Delta = stats.Stamp.Sub(stamp)
@@ -237,6 +338,7 @@ func NewStreamedTeeWriter[T, U any](args NewStreamedTeeWriterArgs[T, U]) core.Wr
 			}
 
 			stamp = stats.Stamp
+			vars.record(stats.Err, stats.Delta)
 			err = errors.Join(err, args.WriterStats.Write(ctx, stats))
 			return
 		},
@@ -259,6 +361,14 @@ type NewBatchedTeeWriterArgs[T any] struct {
 	// CtxKeys is used to extract values from the ctx given to the returned
 	// Reader. These k:v pairs are set to StatsStreamed.CtxMap.
 	CtxKeys []string
+	// Classify is used to set StatsBatched.ErrClass. On nil, defaults to the
+	// Classify func of this pkg.
+	Classify func(error) ErrClass
+	// Expvar enables live counters which are published with expvar in the
+	// "gtl" map under Tag, i.e "reads", "writes", "errors", "inFlight" and
+	// "lastDeltaSeconds". The counters are shared by all tees with the same
+	// Tag, and are visible at e.g /debug/vars when using the expvar handler.
+	Expvar bool
 }
 
 // NewBatchedTeeWriter returns a Writer[[]T] which writes into args.WriterVals
@@ -281,11 +391,17 @@ func NewBatchedTeeWriter[T any](args NewBatchedTeeWriterArgs[T]) core.Writer[[]T
 	if args.Tag == "" {
 		args.Tag = "<unset>"
 	}
+	if args.Classify == nil {
+		args.Classify = Classify
+	}
 
+	vars := newLiveVars(args.Expvar, args.Tag, "writes")
 	stamp := time.Now()
 	return core.WriterImpl[[]T]{
 		Impl: func(ctx context.Context, s []T) (err error) {
+			vars.begin()
 			err = args.WriterVals.Write(ctx, s)
+			vars.end()
 			if err == io.ErrClosedPipe {
 				return
 			}
@@ -294,6 +410,8 @@ func NewBatchedTeeWriter[T any](args NewBatchedTeeWriterArgs[T]) core.Writer[[]T
 			stats.Tag = args.Tag
 			stats.Len = len(s)
 			stats.Err = err
+			stats.ErrMsg = errMsg(err)
+			stats.ErrClass = args.Classify(err)
 			stats.CtxMap = make(map[string]any, len(args.CtxKeys))
 			stats.Stamp = time.Now()
 			stats.Delta = stats.Stamp.Sub(stamp)
@@ -305,6 +423,7 @@ func NewBatchedTeeWriter[T any](args NewBatchedTeeWriterArgs[T]) core.Writer[[]T
 			}
 
 			stamp = stats.Stamp
+			vars.record(stats.Err, stats.Delta)
 			err = errors.Join(err, args.WriterStats.Write(ctx, stats))
 			return
 		},
@@ -684,3 +684,64 @@ func TestNewBatchedTeeWriterWithErrClosedPipe(t *testing.T) {
 	_, err = rws.Read(tvCtx)
 	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
 }
+
+func TestClassify(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+
+	assertEq("class", ErrClassNone, Classify(nil), func(s string) { t.Fatal(s) })
+	assertEq("class", ErrClassEOF, Classify(io.EOF), func(s string) { t.Fatal(s) })
+	assertEq("class", ErrClassClosedPipe, Classify(io.ErrClosedPipe), func(s string) { t.Fatal(s) })
+	assertEq("class", ErrClassCanceled, Classify(context.Canceled), func(s string) { t.Fatal(s) })
+	assertEq("class", ErrClassDeadline, Classify(ctx.Err()), func(s string) { t.Fatal(s) })
+	assertEq("class", ErrClassOther, Classify(tvErr), func(s string) { t.Fatal(s) })
+
+	err := errors.Join(tvErr, io.ErrClosedPipe)
+	assertEq("class", ErrClassClosedPipe, Classify(err), func(s string) { t.Fatal(s) })
+}
+
+func TestStatsStreamedJSONWithErr(t *testing.T) {
+	rw := core.NewReadWriterFrom[StatsStreamed[string]]()
+
+	r := NewStreamedTeeReader(
+		NewStreamedTeeReaderArgs[string, string]{
+			Reader: core.ReaderImpl[string]{
+				Impl: func(ctx context.Context) (string, error) { return "", tvErr },
+			},
+			Writer: rw,
+		},
+	)
+
+	_, err := r.Read(tvCtx)
+	assertEq("err", tvErr.Error(), err.Error(), func(s string) { t.Fatal(s) })
+
+	stat, _ := rw.Read(nil)
+	b, _ := json.Marshal(stat)
+
+	m := make(map[string]any)
+	json.Unmarshal(b, &m)
+	assertEq[any]("err", tvErr.Error(), m["err"], func(s string) { t.Fatal(s) })
+	assertEq[any]("errClass", string(ErrClassOther), m["errClass"], func(s string) { t.Fatal(s) })
+}
+
+func TestNewBatchedTeeWriterWithClassify(t *testing.T) {
+	rw := core.NewReadWriterFrom[StatsBatched]()
+
+	w := NewBatchedTeeWriter(
+		NewBatchedTeeWriterArgs[string]{
+			WriterVals: core.WriterImpl[[]string]{
+				Impl: func(ctx context.Context, s []string) error { return tvErr },
+			},
+			WriterStats: rw,
+			Classify:    func(err error) ErrClass { return "custom" },
+		},
+	)
+
+	err := w.Write(tvCtx, []string{"test1"})
+	assertEq("err", tvErr.Error(), err.Error(), func(s string) { t.Fatal(s) })
+
+	stat, _ := rw.Read(nil)
+	assertEq("errMsg", tvErr.Error(), stat.ErrMsg, func(s string) { t.Fatal(s) })
+	assertEq("errClass", ErrClass("custom"), stat.ErrClass, func(s string) { t.Fatal(s) })
+}
@@ -0,0 +1,333 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// -----------------------------------------------------------------------------
+// Sketch.
+// -----------------------------------------------------------------------------
+
+// Sketch is a mergeable quantile sketch of durations. Durations are counted in
+// logarithmically sized buckets, such that quantiles are accurate within a
+// relative error (e.g 1%), similar to HDR histograms and DDSketch. Memory use
+// depends on the range of durations, not the number of them. The zero value
+// is not usable, see NewSketch. A Sketch is not safe for concurrent use.
+type Sketch struct {
+	gamma   float64
+	lnGamma float64
+	zeros   uint64
+	count   uint64
+	buckets map[int]uint64
+}
+
+// NewSketch returns a Sketch with the given relative accuracy, e.g 0.01 for
+// 1%. On <= 0 or >= 1, the accuracy defaults to 0.01.
+func NewSketch(accuracy float64) *Sketch {
+	if accuracy <= 0 || accuracy >= 1 {
+		accuracy = 0.01
+	}
+
+	gamma := (1 + accuracy) / (1 - accuracy)
+	return &Sketch{
+		gamma:   gamma,
+		lnGamma: math.Log(gamma),
+		buckets: make(map[int]uint64),
+	}
+}
+
+// Add adds a duration to the sketch. Durations <= 0 are counted as 0.
+func (s *Sketch) Add(d time.Duration) {
+	s.count++
+	if d <= 0 {
+		s.zeros++
+		return
+	}
+
+	s.buckets[int(math.Ceil(math.Log(float64(d))/s.lnGamma))]++
+}
+
+// Merge adds all durations counted by 'o' into 's'. Both sketches should have
+// the same accuracy, if not, then 'o' is ignored.
+func (s *Sketch) Merge(o *Sketch) {
+	if o == nil || o.gamma != s.gamma {
+		return
+	}
+
+	s.count += o.count
+	s.zeros += o.zeros
+	for k, v := range o.buckets {
+		s.buckets[k] += v
+	}
+}
+
+// Count returns the number of durations added to the sketch.
+func (s *Sketch) Count() uint64 {
+	return s.count
+}
+
+// Quantile returns the approximate q-quantile (0 <= q <= 1) of the durations
+// added to the sketch, e.g 0.99 for p99. An empty sketch returns 0.
+func (s *Sketch) Quantile(q float64) time.Duration {
+	if s.count == 0 {
+		return 0
+	}
+
+	q = math.Max(0, math.Min(1, q))
+	rank := uint64(math.Ceil(q * float64(s.count)))
+	if rank == 0 {
+		rank = 1
+	}
+	if rank <= s.zeros {
+		return 0
+	}
+
+	keys := make([]int, 0, len(s.buckets))
+	for k := range s.buckets {
+		keys = append(keys, k)
+	}
+
+	sort.Ints(keys)
+
+	n := s.zeros
+	for _, k := range keys {
+		n += s.buckets[k]
+		if n >= rank {
+			// Midpoint (by relative error) of bucket (gamma^(k-1), gamma^k].
+			v := 2 * math.Pow(s.gamma, float64(k)) / (s.gamma + 1)
+			return time.Duration(math.Round(v))
+		}
+	}
+
+	return 0
+}
+
+// -----------------------------------------------------------------------------
+// Summary.
+// -----------------------------------------------------------------------------
+
+// StatsSummary summarizes stats (StatsStreamed or StatsBatched) with the same
+// Tag over a period of time, see NewSummaryStreamedWriter.
+type StatsSummary struct {
+	Tag        string        `json:"tag"`
+	Start      time.Time     `json:"start"`
+	End        time.Time     `json:"end"`
+	Count      int           `json:"count"`
+	ErrCount   int           `json:"errCount"`
+	Throughput float64       `json:"throughput"` // Count per second.
+	Min        time.Duration `json:"min"`
+	Max        time.Duration `json:"max"`
+	Mean       time.Duration `json:"mean"`
+	P50        time.Duration `json:"p50"`
+	P90        time.Duration `json:"p90"`
+	P99        time.Duration `json:"p99"`
+	// Sketch of all Delta values in this summary, it may be merged with
+	// sketches of other summaries for e.g quantiles over longer periods.
+	Sketch *Sketch `json:"-"`
+}
+
+type NewSummaryWriterArgs struct {
+	// Writer is where summaries are written. On nil, the func simply returns
+	// a core.WriteCloserImpl, making it pointless.
+	Writer core.Writer[StatsSummary]
+	// Interval is the period which is summarized. On <= 0, defaults to 10s.
+	Interval time.Duration
+	// Accuracy is the relative accuracy of quantiles, see NewSketch.
+	Accuracy float64
+	// Ticker, if true, starts a goroutine which writes summaries every
+	// Interval until Close, such that streams which go quiet are summarized
+	// as well. Errs from these writes are given by the next write or Close.
+	Ticker bool
+}
+
+// summarizer contains the logic shared by NewSummaryStreamedWriter and
+// NewSummaryBatchedWriter.
+type summarizer struct {
+	mx     sync.Mutex
+	args   NewSummaryWriterArgs
+	start  time.Time
+	tags   []string // Keeps the write order of summaries stable.
+	sums   map[string]*StatsSummary
+	err    error         // From flushes done by tick.
+	stop   chan struct{} // Closed on close, stops tick.
+	closed bool
+}
+
+func newSummarizer(args NewSummaryWriterArgs) *summarizer {
+	if args.Interval <= 0 {
+		args.Interval = time.Second * 10
+	}
+
+	s := &summarizer{
+		args:  args,
+		start: time.Now(),
+		sums:  make(map[string]*StatsSummary),
+		stop:  make(chan struct{}),
+	}
+
+	if args.Ticker {
+		go s.tick()
+	}
+
+	return s
+}
+
+func (s *summarizer) tick() {
+	t := time.NewTicker(s.args.Interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+		}
+
+		s.mx.Lock()
+		if !s.closed && time.Since(s.start) >= s.args.Interval {
+			s.err = errors.Join(s.err, s.flush(context.Background()))
+		}
+		s.mx.Unlock()
+	}
+}
+
+func (s *summarizer) add(ctx context.Context, tag string, n int, err error, d time.Duration) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+
+	sum, ok := s.sums[tag]
+	if !ok {
+		sum = &StatsSummary{Tag: tag, Min: d, Max: d, Sketch: NewSketch(s.args.Accuracy)}
+		s.sums[tag] = sum
+		s.tags = append(s.tags, tag)
+	}
+
+	sum.Count += n
+	if err != nil {
+		sum.ErrCount++
+	}
+
+	sum.Min = min(sum.Min, d)
+	sum.Max = max(sum.Max, d)
+	sum.Mean += d // Sum until flushed.
+	sum.Sketch.Add(d)
+
+	err, s.err = s.err, nil
+	if time.Since(s.start) < s.args.Interval {
+		return err
+	}
+
+	return errors.Join(err, s.flush(ctx))
+}
+
+// flush writes all summaries to s.args.Writer, the mutex must be held.
+func (s *summarizer) flush(ctx context.Context) (err error) {
+	end := time.Now()
+	secs := end.Sub(s.start).Seconds()
+
+	for _, tag := range s.tags {
+		sum := s.sums[tag]
+		sum.Start = s.start
+		sum.End = end
+		sum.Mean /= time.Duration(sum.Sketch.Count())
+		sum.P50 = sum.Sketch.Quantile(0.5)
+		sum.P90 = sum.Sketch.Quantile(0.9)
+		sum.P99 = sum.Sketch.Quantile(0.99)
+		if secs > 0 {
+			sum.Throughput = float64(sum.Count) / secs
+		}
+
+		err = errors.Join(err, s.args.Writer.Write(ctx, *sum))
+	}
+
+	s.start = end
+	s.tags = s.tags[:0]
+	s.sums = make(map[string]*StatsSummary)
+	return err
+}
+
+func (s *summarizer) close() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+	close(s.stop)
+
+	err := s.err
+	s.err = nil
+	return errors.Join(err, s.flush(context.Background()))
+}
+
+// NewSummaryStreamedWriter returns a WriteCloser which aggregates StatsStreamed
+// into one StatsSummary per Tag and period (args.Interval), which are then
+// written to args.Writer. This is intended for high-volume streams, where
+// writing each StatsStreamed would be too noisy, e.g use the returned
+// WriteCloser as the Writer of NewStreamedTeeReader.
+//
+// Summaries are written on the first write after args.Interval has elapsed,
+// and on Close. A stream which goes quiet therefore has its last period
+// written on Close, unless args.Ticker is set, in which case summaries are
+// also written every args.Interval by a goroutine. Errs from args.Writer are
+// joined with errors.Join. Writes after Close give io.ErrClosedPipe. The
+// returned WriteCloser is safe for concurrent use, so it may be shared between
+// several tees.
+//
+// Example:
+//
+//	w := NewSummaryStreamedWriter[int](
+//	    NewSummaryWriterArgs{
+//	        Writer:   core.NewWriterFromValues[StatsSummary](os.Stdout)(nil),
+//	        Interval: time.Minute,
+//	    },
+//	)
+//	defer w.Close()
+//
+//	r := NewStreamedTeeReader(
+//	    NewStreamedTeeReaderArgs[int, int]{Reader: myReader, Writer: w},
+//	)
+func NewSummaryStreamedWriter[U any](args NewSummaryWriterArgs) core.WriteCloser[StatsStreamed[U]] {
+	if args.Writer == nil {
+		return core.WriteCloserImpl[StatsStreamed[U]]{}
+	}
+
+	s := newSummarizer(args)
+	return core.WriteCloserImpl[StatsStreamed[U]]{
+		ImplC: s.close,
+		ImplW: func(ctx context.Context, stats StatsStreamed[U]) error {
+			return s.add(ctx, stats.Tag, 1, stats.Err, stats.Delta)
+		},
+	}
+}
+
+// NewSummaryBatchedWriter is equivalent to NewSummaryStreamedWriter, except
+// that it aggregates StatsBatched, with StatsSummary.Count being the sum of
+// StatsBatched.Len.
+func NewSummaryBatchedWriter(args NewSummaryWriterArgs) core.WriteCloser[StatsBatched] {
+	if args.Writer == nil {
+		return core.WriteCloserImpl[StatsBatched]{}
+	}
+
+	s := newSummarizer(args)
+	return core.WriteCloserImpl[StatsBatched]{
+		ImplC: s.close,
+		ImplW: func(ctx context.Context, stats StatsBatched) error {
+			return s.add(ctx, stats.Tag, stats.Len, stats.Err, stats.Delta)
+		},
+	}
+}
@@ -0,0 +1,146 @@
+package stats
+
+import (
+	"context"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+func tfAssertNear(t *testing.T, subject string, want, have time.Duration, accuracy float64) {
+	if math.Abs(float64(want-have)) > float64(want)*accuracy {
+		t.Fatalf("unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n", subject, want, have)
+	}
+}
+
+func TestSketchIdeal(t *testing.T) {
+	s := NewSketch(0.01)
+	for i := 1; i <= 1000; i++ {
+		s.Add(time.Millisecond * time.Duration(i))
+	}
+
+	assertEq("count", uint64(1000), s.Count(), func(s string) { t.Fatal(s) })
+	tfAssertNear(t, "p50", time.Millisecond*500, s.Quantile(0.5), 0.01)
+	tfAssertNear(t, "p90", time.Millisecond*900, s.Quantile(0.9), 0.01)
+	tfAssertNear(t, "p99", time.Millisecond*990, s.Quantile(0.99), 0.01)
+}
+
+func TestSketchMerge(t *testing.T) {
+	a := NewSketch(0.01)
+	b := NewSketch(0.01)
+	for i := 1; i <= 500; i++ {
+		a.Add(time.Millisecond * time.Duration(i))
+		b.Add(time.Millisecond * time.Duration(i+500))
+	}
+
+	a.Merge(b)
+	assertEq("count", uint64(1000), a.Count(), func(s string) { t.Fatal(s) })
+	tfAssertNear(t, "p50", time.Millisecond*500, a.Quantile(0.5), 0.01)
+	tfAssertNear(t, "p90", time.Millisecond*900, a.Quantile(0.9), 0.01)
+}
+
+func TestSketchEmpty(t *testing.T) {
+	s := NewSketch(0)
+	assertEq("p50", time.Duration(0), s.Quantile(0.5), func(s string) { t.Fatal(s) })
+
+	s.Add(0)
+	assertEq("p50", time.Duration(0), s.Quantile(0.5), func(s string) { t.Fatal(s) })
+}
+
+func TestNewSummaryStreamedWriterIdeal(t *testing.T) {
+	rw := core.NewReadWriterFrom[StatsSummary]()
+	w := NewSummaryStreamedWriter[int](NewSummaryWriterArgs{Writer: rw, Interval: time.Hour})
+
+	for i := 1; i <= 100; i++ {
+		stats := StatsStreamed[int]{Tag: "a", Delta: time.Millisecond * time.Duration(i)}
+		if i%10 == 0 {
+			stats.Err = tvErr
+		}
+
+		err := w.Write(context.Background(), stats)
+		assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	}
+
+	w.Write(context.Background(), StatsStreamed[int]{Tag: "b", Delta: time.Second})
+
+	// Nothing written before the interval has elapsed.
+	_, err := rw.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+
+	sum, err := rw.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("tag", "a", sum.Tag, func(s string) { t.Fatal(s) })
+	assertEq("count", 100, sum.Count, func(s string) { t.Fatal(s) })
+	assertEq("errCount", 10, sum.ErrCount, func(s string) { t.Fatal(s) })
+	assertEq("min", time.Millisecond, sum.Min, func(s string) { t.Fatal(s) })
+	assertEq("max", time.Millisecond*100, sum.Max, func(s string) { t.Fatal(s) })
+	assertEq("mean", time.Microsecond*50500, sum.Mean, func(s string) { t.Fatal(s) })
+	tfAssertNear(t, "p50", time.Millisecond*50, sum.P50, 0.01)
+	tfAssertNear(t, "p99", time.Millisecond*99, sum.P99, 0.01)
+
+	sum, err = rw.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("tag", "b", sum.Tag, func(s string) { t.Fatal(s) })
+	assertEq("count", 1, sum.Count, func(s string) { t.Fatal(s) })
+
+	err = w.Write(context.Background(), StatsStreamed[int]{Tag: "a"})
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewSummaryBatchedWriterWithInterval(t *testing.T) {
+	rw := core.NewReadWriterFrom[StatsSummary]()
+	w := NewSummaryBatchedWriter(NewSummaryWriterArgs{Writer: rw, Interval: time.Millisecond * 10})
+
+	w.Write(context.Background(), StatsBatched{Tag: "a", Len: 3})
+	time.Sleep(time.Millisecond * 20)
+	w.Write(context.Background(), StatsBatched{Tag: "a", Len: 2})
+
+	sum, err := rw.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("count", 5, sum.Count, func(s string) { t.Fatal(s) })
+
+	if sum.Throughput <= 0 {
+		t.Fatalf("unexpected throughput: %v", sum.Throughput)
+	}
+}
+
+func TestNewSummaryStreamedWriterWithNilWriter(t *testing.T) {
+	w := NewSummaryStreamedWriter[int](NewSummaryWriterArgs{})
+
+	err := w.Write(context.Background(), StatsStreamed[int]{})
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewSummaryStreamedWriterWithTicker(t *testing.T) {
+	ch := make(chan StatsSummary, 8)
+	w := NewSummaryStreamedWriter[int](
+		NewSummaryWriterArgs{
+			Writer: core.WriterImpl[StatsSummary]{
+				Impl: func(ctx context.Context, sum StatsSummary) error {
+					ch <- sum
+					return nil
+				},
+			},
+			Interval: time.Millisecond * 10,
+			Ticker:   true,
+		},
+	)
+
+	w.Write(context.Background(), StatsStreamed[int]{Tag: "a"})
+	w.Write(context.Background(), StatsStreamed[int]{Tag: "a"})
+
+	// No more writes, the ticker should flush.
+	select {
+	case sum := <-ch:
+		assertEq("count", 2, sum.Count, func(s string) { t.Fatal(s) })
+	case <-time.After(time.Second):
+		t.Fatal("expected a summary from the ticker")
+	}
+
+	assertEq("err", *new(error), w.Close(), func(s string) { t.Fatal(s) })
+}
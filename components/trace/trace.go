@@ -0,0 +1,250 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+// Span represents a single traced Read or Write call.
+type Span struct {
+	TraceID  string         `json:"traceId"`
+	SpanID   string         `json:"spanId"`
+	ParentID string         `json:"parentId,omitempty"`
+	Name     string         `json:"name"`
+	Start    time.Time      `json:"start"`
+	End      time.Time      `json:"end"`
+	Duration time.Duration  `json:"duration"`
+	Err      string         `json:"err,omitempty"`
+	Attrs    map[string]any `json:"attrs,omitempty"`
+}
+
+// SpanContext identifies a span, it is what is propagated between stages.
+type SpanContext struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+type spanCtxKey struct{}
+
+// ContextWithSpan returns a copy of 'ctx' which carries 'sc'. Readers and
+// writers from this pkg use it as the parent of their spans.
+func ContextWithSpan(ctx context.Context, sc SpanContext) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, spanCtxKey{}, sc)
+}
+
+// SpanFromContext returns the SpanContext carried by 'ctx', if any.
+func SpanFromContext(ctx context.Context) (sc SpanContext, ok bool) {
+	if ctx == nil {
+		return
+	}
+
+	sc, ok = ctx.Value(spanCtxKey{}).(SpanContext)
+	return
+}
+
+// Link carries a SpanContext between stages which do not share ctx values,
+// e.g a Reader and Writer used with eventloop.New. Give the same Link to
+// NewReader and NewWriter; the Reader sets it after each Read, and spans from
+// the Writer become children of the span from the preceding Read (unless the
+// ctx given to the Writer carries a span). The zero value is ready for use,
+// and it is safe for concurrent use.
+type Link struct {
+	mx sync.Mutex
+	sc SpanContext
+}
+
+func (l *Link) get() (sc SpanContext, ok bool) {
+	if l == nil {
+		return
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	return l.sc, l.sc.SpanID != ""
+}
+
+func (l *Link) set(sc SpanContext) {
+	if l == nil {
+		return
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.sc = sc
+}
+
+func newID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// start starts a span as a child of the span in 'ctx', or of the span in 'l'
+// if 'ctx' has none (nil 'l' is valid). Without a parent, a new trace is
+// started. The returned ctx carries the new span.
+func start(ctx context.Context, l *Link, name string, keys []string) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	parent, ok := SpanFromContext(ctx)
+	if !ok {
+		parent, ok = l.get()
+	}
+
+	s := Span{}
+	s.TraceID = parent.TraceID
+	s.SpanID = newID(8)
+	s.ParentID = parent.SpanID
+	s.Name = name
+	s.Start = time.Now()
+
+	if !ok {
+		s.TraceID = newID(16)
+	}
+
+	if len(keys) > 0 {
+		s.Attrs = make(map[string]any, len(keys))
+		for _, k := range keys {
+			s.Attrs[k] = ctx.Value(k)
+		}
+	}
+
+	return ContextWithSpan(ctx, SpanContext{s.TraceID, s.SpanID}), s
+}
+
+func end(s Span, err error) Span {
+	s.End = time.Now()
+	s.Duration = s.End.Sub(s.Start)
+	if err != nil {
+		s.Err = err.Error()
+	}
+
+	return s
+}
+
+type NewReaderArgs[T any] struct {
+	// Reader is what the func reads from. On nil, the func simply returns
+	// a core.ReaderImpl[T], making it pointless. It is given a ctx which
+	// carries the span of the Read call, so nested stages become children.
+	Reader core.Reader[T]
+	// Writer is where spans are exported. On nil, spans are not exported but
+	// are still propagated. Errors coming from here will be wrapped with any
+	// err returned from Reader using errors.Join.
+	Writer core.Writer[Span]
+	// Name will be set to Span.Name. If empty, it will be set to "<unset>".
+	Name string
+	// CtxKeys is used to extract values from the ctx given to the returned
+	// Reader. These k:v pairs are set to Span.Attrs.
+	CtxKeys []string
+	// Link is optional, see docs for Link.
+	Link *Link
+}
+
+// NewReader returns a Reader which starts a span for each Read call, reads
+// from args.Reader with a ctx carrying that span, and exports the span to
+// args.Writer. Spans are not exported when args.Reader returns io.EOF.
+// See args for details.
+//
+// Example:
+//
+//	link := &Link{}
+//	spans := core.NewWriterFromValues[Span](os.Stdout)(nil) // JSON lines.
+//
+//	eventloop.New(
+//	    eventloop.NewArgs[int]{
+//	        Reader: NewReader(
+//	            NewReaderArgs[int]{Reader: r, Writer: spans, Name: "read", Link: link},
+//	        ),
+//	        Writer: NewWriter(
+//	            NewWriterArgs[int]{WriterVals: w, WriterSpans: spans, Name: "write", Link: link},
+//	        ),
+//	    },
+//	)
+func NewReader[T any](args NewReaderArgs[T]) core.Reader[T] {
+	if args.Reader == nil {
+		return core.ReaderImpl[T]{}
+	}
+	if args.Writer == nil {
+		args.Writer = core.WriterImpl[Span]{Impl: func(context.Context, Span) error { return nil }}
+	}
+	if args.Name == "" {
+		args.Name = "<unset>"
+	}
+
+	return core.ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			sctx, s := start(ctx, nil, args.Name, args.CtxKeys)
+
+			val, err = args.Reader.Read(sctx)
+			if err == io.EOF {
+				return
+			}
+
+			s = end(s, err)
+			args.Link.set(SpanContext{s.TraceID, s.SpanID})
+			err = errors.Join(err, args.Writer.Write(ctx, s))
+			return
+		},
+	}
+}
+
+type NewWriterArgs[T any] struct {
+	// WriterVals is what the returned Writer writes to. On nil, the func simply
+	// returns a core.WriterImpl[T], making it pointless. It is given a ctx which
+	// carries the span of the Write call, so nested stages become children.
+	WriterVals core.Writer[T]
+	// WriterSpans is where spans are exported. On nil, spans are not exported
+	// but are still propagated. Errors coming from here will be wrapped with
+	// any err returned from WriterVals using errors.Join.
+	WriterSpans core.Writer[Span]
+	// Name will be set to Span.Name. If empty, it will be set to "<unset>".
+	Name string
+	// CtxKeys is used to extract values from the ctx given to the returned
+	// Writer. These k:v pairs are set to Span.Attrs.
+	CtxKeys []string
+	// Link is optional, see docs for Link.
+	Link *Link
+}
+
+// NewWriter returns a Writer which starts a span for each Write call, writes
+// to args.WriterVals with a ctx carrying that span, and exports the span to
+// args.WriterSpans. Spans are not exported when args.WriterVals returns
+// io.ErrClosedPipe. See args for details.
+func NewWriter[T any](args NewWriterArgs[T]) core.Writer[T] {
+	if args.WriterVals == nil {
+		return core.WriterImpl[T]{}
+	}
+	if args.WriterSpans == nil {
+		args.WriterSpans = core.WriterImpl[Span]{Impl: func(context.Context, Span) error { return nil }}
+	}
+	if args.Name == "" {
+		args.Name = "<unset>"
+	}
+
+	return core.WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			sctx, s := start(ctx, args.Link, args.Name, args.CtxKeys)
+
+			err = args.WriterVals.Write(sctx, val)
+			if err == io.ErrClosedPipe {
+				return
+			}
+
+			s = end(s, err)
+			err = errors.Join(err, args.WriterSpans.Write(ctx, s))
+			return
+		},
+	}
+}
@@ -0,0 +1,167 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/crunchypi/gtl/core"
+)
+
+var tvCtxKey = "testKey"
+var tvCtxVal = "testVal"
+var tvCtx = context.WithValue(context.Background(), tvCtxKey, tvCtxVal)
+var tvErr = errors.New("test error")
+
+func assertEq[T any](subject string, want T, have T, f func(string)) {
+	if f == nil {
+		return
+	}
+
+	ab, _ := json.Marshal(want)
+	bb, _ := json.Marshal(have)
+
+	as := string(ab)
+	bs := string(bb)
+
+	if as == bs {
+		return
+	}
+
+	s := "unexpected '%v':\n\twant: '%v'\n\thave: '%v'\n"
+	f(fmt.Sprintf(s, subject, as, bs))
+}
+
+func tfReadAll[T any](ctx context.Context, r core.Reader[T]) ([]T, error) {
+	var v T
+	var s = make([]T, 0, 8)
+	var err error
+
+	for v, err = r.Read(ctx); err == nil; v, err = r.Read(ctx) {
+		s = append(s, v)
+	}
+
+	return s, err
+}
+
+func TestNewReaderIdeal(t *testing.T) {
+	rw := core.NewReadWriterFrom[Span]()
+
+	r := NewReader(
+		NewReaderArgs[int]{
+			Reader:  core.NewReaderFrom(1, 2),
+			Writer:  rw,
+			Name:    "test",
+			CtxKeys: []string{tvCtxKey},
+		},
+	)
+
+	vals, err := tfReadAll(tvCtx, r)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2}, vals, func(s string) { t.Fatal(s) })
+
+	spans, _ := tfReadAll(context.Background(), core.Reader[Span](rw))
+	assertEq("len", 2, len(spans), func(s string) { t.Fatal(s) })
+	assertEq("name", "test", spans[0].Name, func(s string) { t.Fatal(s) })
+	assertEq("attrs", map[string]any{tvCtxKey: tvCtxVal}, spans[0].Attrs, func(s string) { t.Fatal(s) })
+	assertEq("parent", "", spans[0].ParentID, func(s string) { t.Fatal(s) })
+	assertEq("traceId len", 32, len(spans[0].TraceID), func(s string) { t.Fatal(s) })
+	assertEq("spanId len", 16, len(spans[0].SpanID), func(s string) { t.Fatal(s) })
+
+	if spans[0].TraceID == spans[1].TraceID {
+		t.Fatal("expected a new trace per read")
+	}
+}
+
+func TestNewReaderWithNested(t *testing.T) {
+	rw := core.NewReadWriterFrom[Span]()
+
+	inner := NewReader(NewReaderArgs[int]{Reader: core.NewReaderFrom(1), Writer: rw, Name: "inner"})
+	outer := NewReader(NewReaderArgs[int]{Reader: inner, Writer: rw, Name: "outer"})
+
+	_, err := outer.Read(context.Background())
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	i, _ := rw.Read(nil)
+	o, _ := rw.Read(nil)
+	assertEq("name", "inner", i.Name, func(s string) { t.Fatal(s) })
+	assertEq("name", "outer", o.Name, func(s string) { t.Fatal(s) })
+	assertEq("traceId", o.TraceID, i.TraceID, func(s string) { t.Fatal(s) })
+	assertEq("parentId", o.SpanID, i.ParentID, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithErr(t *testing.T) {
+	rw := core.NewReadWriterFrom[Span]()
+
+	r := NewReader(
+		NewReaderArgs[int]{
+			Reader: core.ReaderImpl[int]{Impl: func(context.Context) (int, error) { return 0, tvErr }},
+			Writer: rw,
+		},
+	)
+
+	_, err := r.Read(nil)
+	assertEq("err", tvErr.Error(), err.Error(), func(s string) { t.Fatal(s) })
+
+	s, _ := rw.Read(nil)
+	assertEq("name", "<unset>", s.Name, func(s string) { t.Fatal(s) })
+	assertEq("err", tvErr.Error(), s.Err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithNilReader(t *testing.T) {
+	r := NewReader(NewReaderArgs[int]{})
+
+	_, err := r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithLink(t *testing.T) {
+	rw := core.NewReadWriterFrom[Span]()
+	link := &Link{}
+
+	r := NewReader(NewReaderArgs[int]{Reader: core.NewReaderFrom(1), Writer: rw, Link: link})
+	w := NewWriter(
+		NewWriterArgs[int]{
+			WriterVals: core.WriterImpl[int]{
+				Impl: func(ctx context.Context, v int) error {
+					// Nested stages see the span of the write.
+					_, ok := SpanFromContext(ctx)
+					assertEq("ok", true, ok, func(s string) { t.Fatal(s) })
+					return nil
+				},
+			},
+			WriterSpans: rw,
+			Link:        link,
+		},
+	)
+
+	v, _ := r.Read(nil)
+	err := w.Write(nil, v)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+
+	rs, _ := rw.Read(nil)
+	ws, _ := rw.Read(nil)
+	assertEq("traceId", rs.TraceID, ws.TraceID, func(s string) { t.Fatal(s) })
+	assertEq("parentId", rs.SpanID, ws.ParentID, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithErrClosedPipe(t *testing.T) {
+	rw := core.NewReadWriterFrom[Span]()
+	w := NewWriter(NewWriterArgs[int]{WriterVals: core.WriterImpl[int]{}, WriterSpans: rw})
+
+	err := w.Write(nil, 1)
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+
+	_, err = rw.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithNilWriter(t *testing.T) {
+	w := NewWriter(NewWriterArgs[int]{})
+
+	err := w.Write(nil, 1)
+	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
+}
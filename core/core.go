@@ -76,6 +76,16 @@ func (impl DecoderImpl) Decode(d any) error {
 	return impl.Impl(d)
 }
 
+// -----------------------------------------------------------------------------
+// Pair.
+// -----------------------------------------------------------------------------
+
+// Pair groups two values, e.g values read positionally with NewReaderWithZip.
+type Pair[T, U any] struct {
+	A T `json:"a"`
+	B U `json:"b"`
+}
+
 // -----------------------------------------------------------------------------
 // Implementation io.Reader, io.Writer, io.ReadWriter and closer variants.
 // -----------------------------------------------------------------------------
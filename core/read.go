@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"time"
 )
 
 // -----------------------------------------------------------------------------
@@ -318,3 +320,292 @@ func NewReaderWithUnbatching[T any](r Reader[[]T]) Reader[T] {
 		},
 	}
 }
+
+// NewReaderWithTake returns a Reader which reads 'n' values from 'r' and then
+// returns io.EOF. Nil 'r' returns an empty non-nil Reader, n <= 0 gives io.EOF
+// on the first read.
+//
+// Example:
+//
+//	r := NewReaderWithTake(NewReaderFrom(1, 2, 3), 2)
+//
+//	t.Log(r.Read(nil)) // 1, nil
+//	t.Log(r.Read(nil)) // 2, nil
+//	t.Log(r.Read(nil)) // 0, io.EOF
+func NewReaderWithTake[T any](r Reader[T], n int) Reader[T] {
+	if r == nil {
+		return ReaderImpl[T]{}
+	}
+
+	i := 0
+	return ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			if i >= n {
+				return val, io.EOF
+			}
+
+			val, err = r.Read(ctx)
+			if err == nil {
+				i++
+			}
+
+			return
+		},
+	}
+}
+
+// NewReaderWithSkip returns a Reader which discards the first 'n' values from
+// 'r' and then passes along the rest. Nil 'r' returns an empty non-nil Reader.
+// Errors from 'r' while skipping are returned as-is.
+//
+// Example:
+//
+//	r := NewReaderWithSkip(NewReaderFrom(1, 2, 3), 2)
+//
+//	t.Log(r.Read(nil)) // 3, nil
+//	t.Log(r.Read(nil)) // 0, io.EOF
+func NewReaderWithSkip[T any](r Reader[T], n int) Reader[T] {
+	if r == nil {
+		return ReaderImpl[T]{}
+	}
+
+	i := 0
+	return ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			for ; i < n; i++ {
+				_, err = r.Read(ctx)
+				if err != nil {
+					return
+				}
+			}
+
+			return r.Read(ctx)
+		},
+	}
+}
+
+// NewReaderWithTakeWhile returns a Reader which passes along values from 'r'
+// as long as 'f' returns true for them. The first value for which 'f' returns
+// false is discarded and io.EOF is returned from then on. Nil 'r' returns an
+// empty non-nil Reader, nil 'f' passes along all values.
+//
+// Example:
+//
+//	r := NewReaderWithTakeWhile(NewReaderFrom(1, 2, 3), func(v int) bool {
+//	    return v < 2
+//	})
+//
+//	t.Log(r.Read(nil)) // 1, nil
+//	t.Log(r.Read(nil)) // 0, io.EOF
+func NewReaderWithTakeWhile[T any](r Reader[T], f func(T) bool) Reader[T] {
+	if r == nil {
+		return ReaderImpl[T]{}
+	}
+	if f == nil {
+		return r
+	}
+
+	done := false
+	return ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			if done {
+				return val, io.EOF
+			}
+
+			val, err = r.Read(ctx)
+			if err != nil {
+				return
+			}
+
+			if !f(val) {
+				done = true
+				return *new(T), io.EOF
+			}
+
+			return
+		},
+	}
+}
+
+// NewReaderWithTimeLimit returns a Reader which passes along values from 'r'
+// until 'd' has elapsed since the first read, after which io.EOF is returned.
+// Nil 'r' returns an empty non-nil Reader. Note that a read which is in
+// progress when the limit is reached is not interrupted.
+//
+// Example:
+//
+//	// Read for 30 seconds.
+//	r := NewReaderWithTimeLimit(myReader, time.Second*30)
+func NewReaderWithTimeLimit[T any](r Reader[T], d time.Duration) Reader[T] {
+	if r == nil {
+		return ReaderImpl[T]{}
+	}
+
+	var deadline time.Time
+	return ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			if deadline.IsZero() {
+				deadline = time.Now().Add(d)
+			}
+
+			if !time.Now().Before(deadline) {
+				return val, io.EOF
+			}
+
+			return r.Read(ctx)
+		},
+	}
+}
+
+// NewReaderWithCloseOnEOF returns a Reader which passes along values from 'r'
+// and closes 'c' the first time 'r' returns io.EOF. This is intended to be
+// used with the other modifiers, e.g NewReaderWithTake, for closing the
+// underlying ReadCloser when a limit is reached. Nil 'r' returns an empty
+// non-nil Reader, nil 'c' simply passes along values from 'r'. If closing
+// 'c' fails, then the err is joined with io.EOF using errors.Join.
+//
+// Example:
+//
+//	rc := myReadCloser()
+//	r := NewReaderWithCloseOnEOF(NewReaderWithTake(rc, 1000), rc)
+func NewReaderWithCloseOnEOF[T any](r Reader[T], c io.Closer) Reader[T] {
+	if r == nil {
+		return ReaderImpl[T]{}
+	}
+	if c == nil {
+		return r
+	}
+
+	closed := false
+	return ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			val, err = r.Read(ctx)
+			if err != io.EOF || closed {
+				return
+			}
+
+			closed = true
+			if cerr := c.Close(); cerr != nil {
+				err = errors.Join(err, cerr)
+			}
+
+			return
+		},
+	}
+}
+
+// NewReaderWithConcat returns a Reader which reads from each of the given
+// readers back to back, i.e it reads from the first until it returns io.EOF,
+// then the second, and so on. Nil readers are skipped, and errs other than
+// io.EOF are returned as-is without moving on to the next reader.
+//
+// Example:
+//
+//	r := NewReaderWithConcat(NewReaderFrom(1, 2), NewReaderFrom(3))
+//
+//	t.Log(r.Read(nil)) // 1, nil
+//	t.Log(r.Read(nil)) // 2, nil
+//	t.Log(r.Read(nil)) // 3, nil
+//	t.Log(r.Read(nil)) // 0, io.EOF
+func NewReaderWithConcat[T any](rs ...Reader[T]) Reader[T] {
+	i := 0
+	return ReaderImpl[T]{
+		Impl: func(ctx context.Context) (val T, err error) {
+			for ; i < len(rs); i++ {
+				if rs[i] == nil {
+					continue
+				}
+
+				val, err = rs[i].Read(ctx)
+				if err != io.EOF {
+					return
+				}
+			}
+
+			return *new(T), io.EOF
+		},
+	}
+}
+
+// ZipMode defines how NewReaderWithZip behaves when its readers have
+// different lengths.
+type ZipMode int
+
+const (
+	// ZipShortest makes the reader return io.EOF as soon as either of the
+	// zipped readers returns io.EOF.
+	ZipShortest ZipMode = iota
+	// ZipLongest makes the reader continue until both zipped readers have
+	// returned io.EOF, using zero values in place of the exhausted one.
+	ZipLongest
+	// ZipStrict makes the reader return io.ErrUnexpectedEOF if one of the
+	// zipped readers returns io.EOF before the other.
+	ZipStrict
+)
+
+// NewReaderWithZip returns a Reader which reads from 'a' and 'b' in lockstep
+// and pairs the values positionally. The 'mode' decides what happens when one
+// of them returns io.EOF before the other (see ZipMode). Nil 'a' or 'b' is
+// treated as a Reader which is immediately exhausted. Errs other than io.EOF
+// are returned as-is.
+//
+// Example:
+//
+//	r := NewReaderWithZip(NewReaderFrom(1, 2), NewReaderFrom("a"), ZipLongest)
+//
+//	t.Log(r.Read(nil)) // {1 "a"}, nil
+//	t.Log(r.Read(nil)) // {2 ""}, nil
+//	t.Log(r.Read(nil)) // {0 ""}, io.EOF
+func NewReaderWithZip[T, U any](a Reader[T], b Reader[U], mode ZipMode) Reader[Pair[T, U]] {
+	if a == nil {
+		a = ReaderImpl[T]{}
+	}
+	if b == nil {
+		b = ReaderImpl[U]{}
+	}
+
+	doneA := false
+	doneB := false
+	return ReaderImpl[Pair[T, U]]{
+		Impl: func(ctx context.Context) (p Pair[T, U], err error) {
+			if doneA && doneB {
+				return p, io.EOF
+			}
+
+			if !doneA {
+				p.A, err = a.Read(ctx)
+				switch {
+				case err == io.EOF:
+					doneA = true
+				case err != nil:
+					return Pair[T, U]{}, err
+				}
+			}
+
+			if !doneB {
+				p.B, err = b.Read(ctx)
+				switch {
+				case err == io.EOF:
+					doneB = true
+				case err != nil:
+					return Pair[T, U]{}, err
+				}
+			}
+
+			switch {
+			case doneA && doneB:
+				return Pair[T, U]{}, io.EOF
+			case !doneA && !doneB:
+				return p, nil
+			case mode == ZipLongest:
+				return p, nil
+			case mode == ZipStrict:
+				doneA, doneB = true, true
+				return Pair[T, U]{}, io.ErrUnexpectedEOF
+			default:
+				doneA, doneB = true, true
+				return Pair[T, U]{}, io.EOF
+			}
+		},
+	}
+}
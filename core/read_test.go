@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"testing"
+	"time"
 )
 
 // -----------------------------------------------------------------------------
@@ -300,3 +301,170 @@ func TestNewReaderWithUnbatchingWithEmptyBatchAndErr(t *testing.T) {
 	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
 	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
 }
+
+func TestNewReaderWithTakeIdeal(t *testing.T) {
+	r := NewReaderWithTake(NewReaderFrom(1, 2, 3), 2)
+
+	val, err := r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithTakeWithNilReader(t *testing.T) {
+	r := NewReaderWithTake[int](nil, 2)
+
+	_, err := r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithSkipIdeal(t *testing.T) {
+	r := NewReaderWithSkip(NewReaderFrom(1, 2, 3), 2)
+
+	val, err := r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 3, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithSkipWithShortReader(t *testing.T) {
+	r := NewReaderWithSkip(NewReaderFrom(1), 2)
+
+	_, err := r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithTakeWhileIdeal(t *testing.T) {
+	r := NewReaderWithTakeWhile(NewReaderFrom(1, 2, 3, 1), func(v int) bool { return v < 3 })
+
+	val, err := r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 2, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithTimeLimitIdeal(t *testing.T) {
+	r := NewReaderWithTimeLimit(NewReaderFrom(1, 2), time.Millisecond*10)
+
+	val, err := r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", 1, val, func(s string) { t.Fatal(s) })
+
+	time.Sleep(time.Millisecond * 20)
+
+	val, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("val", 0, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithCloseOnEOFIdeal(t *testing.T) {
+	n := 0
+	rc := ReadCloserImpl[int]{}
+	rc.ImplC = func() error { n++; return nil }
+	rc.ImplR = NewReaderFrom(1, 2, 3).Read
+
+	r := NewReaderWithCloseOnEOF(NewReaderWithTake(rc, 1), rc)
+
+	_, err := r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("closed", 0, n, func(s string) { t.Fatal(s) })
+
+	_, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("closed", 1, n, func(s string) { t.Fatal(s) })
+
+	_, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("closed", 1, n, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithConcatIdeal(t *testing.T) {
+	r := NewReaderWithConcat(NewReaderFrom(1, 2), nil, NewReaderFrom[int](), NewReaderFrom(3))
+
+	vals := make([]int, 0, 3)
+	val, err := r.Read(nil)
+	for ; err == nil; val, err = r.Read(nil) {
+		vals = append(vals, val)
+	}
+
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("vals", []int{1, 2, 3}, vals, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithConcatWithNoReaders(t *testing.T) {
+	r := NewReaderWithConcat[int]()
+
+	_, err := r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithZipShortest(t *testing.T) {
+	r := NewReaderWithZip(NewReaderFrom(1, 2), NewReaderFrom("a"), ZipShortest)
+
+	val, err := r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", Pair[int, string]{1, "a"}, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("val", Pair[int, string]{}, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithZipLongest(t *testing.T) {
+	r := NewReaderWithZip(NewReaderFrom(1, 2), NewReaderFrom("a"), ZipLongest)
+
+	val, err := r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", Pair[int, string]{1, "a"}, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", Pair[int, string]{2, ""}, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+	assertEq("val", Pair[int, string]{}, val, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithZipStrict(t *testing.T) {
+	r := NewReaderWithZip(NewReaderFrom(1, 2), NewReaderFrom("a"), ZipStrict)
+
+	val, err := r.Read(nil)
+	assertEq("err", *new(error), err, func(s string) { t.Fatal(s) })
+	assertEq("val", Pair[int, string]{1, "a"}, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", io.ErrUnexpectedEOF, err, func(s string) { t.Fatal(s) })
+	assertEq("val", Pair[int, string]{}, val, func(s string) { t.Fatal(s) })
+
+	val, err = r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
+
+func TestNewReaderWithZipWithNilReader(t *testing.T) {
+	r := NewReaderWithZip[int, int](nil, NewReaderFrom(1), ZipShortest)
+
+	_, err := r.Read(nil)
+	assertEq("err", io.EOF, err, func(s string) { t.Fatal(s) })
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"time"
 )
 
 // -----------------------------------------------------------------------------
@@ -287,3 +289,159 @@ func NewWriterWithUnbatching[T any](w Writer[T]) Writer[[]T] {
 		},
 	}
 }
+
+// NewWriterWithTake returns a Writer which writes the first 'n' values into
+// 'w' and then returns io.ErrClosedPipe. Nil 'w' returns an empty non-nil
+// Writer, n <= 0 gives io.ErrClosedPipe on the first write.
+//
+// Example:
+//
+//	w := NewWriterWithTake(myWriter, 1)
+//	w.Write(nil, 1) // nil
+//	w.Write(nil, 2) // io.ErrClosedPipe
+func NewWriterWithTake[T any](w Writer[T], n int) Writer[T] {
+	if w == nil {
+		return WriterImpl[T]{}
+	}
+
+	i := 0
+	return WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			if i >= n {
+				return io.ErrClosedPipe
+			}
+
+			err = w.Write(ctx, val)
+			if err == nil {
+				i++
+			}
+
+			return
+		},
+	}
+}
+
+// NewWriterWithSkip returns a Writer which discards the first 'n' values
+// written to it (nil err), and then writes the rest into 'w'. Nil 'w' returns
+// an empty non-nil Writer.
+//
+// Example:
+//
+//	w := NewWriterWithSkip(myWriter, 1)
+//	w.Write(nil, 1) // nil, discarded.
+//	w.Write(nil, 2) // nil, written to myWriter.
+func NewWriterWithSkip[T any](w Writer[T], n int) Writer[T] {
+	if w == nil {
+		return WriterImpl[T]{}
+	}
+
+	i := 0
+	return WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			if i < n {
+				i++
+				return
+			}
+
+			return w.Write(ctx, val)
+		},
+	}
+}
+
+// NewWriterWithTakeWhile returns a Writer which writes values into 'w' as long
+// as 'f' returns true for them. The first value for which 'f' returns false is
+// discarded and io.ErrClosedPipe is returned from then on. Nil 'w' returns an
+// empty non-nil Writer, nil 'f' writes all values.
+//
+// Example:
+//
+//	w := NewWriterWithTakeWhile(myWriter, func(v int) bool { return v < 2 })
+//	w.Write(nil, 1) // nil
+//	w.Write(nil, 2) // io.ErrClosedPipe
+//	w.Write(nil, 1) // io.ErrClosedPipe
+func NewWriterWithTakeWhile[T any](w Writer[T], f func(T) bool) Writer[T] {
+	if w == nil {
+		return WriterImpl[T]{}
+	}
+	if f == nil {
+		return w
+	}
+
+	done := false
+	return WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			if done || !f(val) {
+				done = true
+				return io.ErrClosedPipe
+			}
+
+			return w.Write(ctx, val)
+		},
+	}
+}
+
+// NewWriterWithTimeLimit returns a Writer which writes values into 'w' until
+// 'd' has elapsed since the first write, after which io.ErrClosedPipe is
+// returned. Nil 'w' returns an empty non-nil Writer.
+//
+// Example:
+//
+//	// Write for 30 seconds.
+//	w := NewWriterWithTimeLimit(myWriter, time.Second*30)
+func NewWriterWithTimeLimit[T any](w Writer[T], d time.Duration) Writer[T] {
+	if w == nil {
+		return WriterImpl[T]{}
+	}
+
+	var deadline time.Time
+	return WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			if deadline.IsZero() {
+				deadline = time.Now().Add(d)
+			}
+
+			if !time.Now().Before(deadline) {
+				return io.ErrClosedPipe
+			}
+
+			return w.Write(ctx, val)
+		},
+	}
+}
+
+// NewWriterWithCloseOnClosedPipe returns a Writer which writes values into 'w'
+// and closes 'c' the first time 'w' returns io.ErrClosedPipe. This is intended
+// to be used with the other modifiers, e.g NewWriterWithTake, for closing the
+// underlying WriteCloser when a limit is reached. Nil 'w' returns an empty
+// non-nil Writer, nil 'c' simply writes values into 'w'. If closing 'c'
+// fails, then the err is joined with io.ErrClosedPipe using errors.Join.
+//
+// Example:
+//
+//	wc := myWriteCloser()
+//	w := NewWriterWithCloseOnClosedPipe(NewWriterWithTake(wc, 1000), wc)
+func NewWriterWithCloseOnClosedPipe[T any](w Writer[T], c io.Closer) Writer[T] {
+	if w == nil {
+		return WriterImpl[T]{}
+	}
+	if c == nil {
+		return w
+	}
+
+	closed := false
+	return WriterImpl[T]{
+		Impl: func(ctx context.Context, val T) (err error) {
+			err = w.Write(ctx, val)
+			if err != io.ErrClosedPipe || closed {
+				return
+			}
+
+			closed = true
+			if cerr := c.Close(); cerr != nil {
+				err = errors.Join(err, cerr)
+			}
+
+			return
+		},
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"io"
 	"testing"
+	"time"
 )
 
 // -----------------------------------------------------------------------------
@@ -261,3 +262,65 @@ func TestWriterWithUnbatchingWithCustomErrr(t *testing.T) {
 	err := sw.Write(nil, []int{1, 2})
 	assertEq("err", io.ErrClosedPipe, err, func(s string) { t.Fatal(s) })
 }
+
+func TestNewWriterWithTakeIdeal(t *testing.T) {
+	s := make([]int, 0, 2)
+	w := NewWriterWithTake(newSliceWriter(&s), 1)
+
+	assertEq("err", *new(error), w.Write(nil, 1), func(s string) { t.Fatal(s) })
+	assertEq("err", io.ErrClosedPipe, w.Write(nil, 2), func(s string) { t.Fatal(s) })
+	assertEq("val", []int{1}, s, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithTakeWithNilWriter(t *testing.T) {
+	w := NewWriterWithTake[int](nil, 1)
+	assertEq("err", io.ErrClosedPipe, w.Write(nil, 1), func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithSkipIdeal(t *testing.T) {
+	s := make([]int, 0, 2)
+	w := NewWriterWithSkip(newSliceWriter(&s), 1)
+
+	assertEq("err", *new(error), w.Write(nil, 1), func(s string) { t.Fatal(s) })
+	assertEq("err", *new(error), w.Write(nil, 2), func(s string) { t.Fatal(s) })
+	assertEq("val", []int{2}, s, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithTakeWhileIdeal(t *testing.T) {
+	s := make([]int, 0, 2)
+	w := NewWriterWithTakeWhile(newSliceWriter(&s), func(v int) bool { return v < 2 })
+
+	assertEq("err", *new(error), w.Write(nil, 1), func(s string) { t.Fatal(s) })
+	assertEq("err", io.ErrClosedPipe, w.Write(nil, 2), func(s string) { t.Fatal(s) })
+	assertEq("err", io.ErrClosedPipe, w.Write(nil, 1), func(s string) { t.Fatal(s) })
+	assertEq("val", []int{1}, s, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithTimeLimitIdeal(t *testing.T) {
+	s := make([]int, 0, 2)
+	w := NewWriterWithTimeLimit(newSliceWriter(&s), time.Millisecond*10)
+
+	assertEq("err", *new(error), w.Write(nil, 1), func(s string) { t.Fatal(s) })
+	time.Sleep(time.Millisecond * 20)
+	assertEq("err", io.ErrClosedPipe, w.Write(nil, 2), func(s string) { t.Fatal(s) })
+	assertEq("val", []int{1}, s, func(s string) { t.Fatal(s) })
+}
+
+func TestNewWriterWithCloseOnClosedPipeIdeal(t *testing.T) {
+	n := 0
+	s := make([]int, 0, 2)
+	wc := WriteCloserImpl[int]{}
+	wc.ImplC = func() error { n++; return nil }
+	wc.ImplW = newSliceWriter(&s).Write
+
+	w := NewWriterWithCloseOnClosedPipe(NewWriterWithTake(wc, 1), wc)
+
+	assertEq("err", *new(error), w.Write(nil, 1), func(s string) { t.Fatal(s) })
+	assertEq("closed", 0, n, func(s string) { t.Fatal(s) })
+
+	assertEq("err", io.ErrClosedPipe, w.Write(nil, 2), func(s string) { t.Fatal(s) })
+	assertEq("closed", 1, n, func(s string) { t.Fatal(s) })
+
+	assertEq("err", io.ErrClosedPipe, w.Write(nil, 3), func(s string) { t.Fatal(s) })
+	assertEq("closed", 1, n, func(s string) { t.Fatal(s) })
+}